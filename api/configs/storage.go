@@ -0,0 +1,54 @@
+package configs
+
+import (
+	"api/storage"
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// ConnectStorage picks the file storage backend from STORAGE_DRIVER:
+// "local" (the default) writes below UPLOAD_DIR, "s3" uses an S3-compatible bucket.
+func ConnectStorage() storage.Storage {
+	godotenv.Load()
+
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "", "local":
+		uploadDir := os.Getenv("UPLOAD_DIR")
+		if uploadDir == "" {
+			uploadDir = "uploads"
+		}
+		store, err := storage.NewLocalStorage(uploadDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return store
+
+	case "s3":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		store, err := storage.NewS3Storage(ctx, storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return store
+
+	default:
+		log.Fatalf("Unknown STORAGE_DRIVER %q", os.Getenv("STORAGE_DRIVER"))
+		return nil
+	}
+}
+
+// File storage instance shared by profile pictures and attachments
+var Store storage.Storage = ConnectStorage()
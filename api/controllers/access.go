@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	apperrors "api/errors"
+	"api/logger"
+	"api/models"
+	"api/utils"
+	"context"
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	errInvalidTaskID = errors.New("invalid task ID")
+	errTaskNotFound  = errors.New("task not found")
+	errTaskForbidden = errors.New("task action forbidden")
+)
+
+// maxRoleDepth bounds how far up the subtask hierarchy roles are inherited
+const maxRoleDepth = 10
+
+// resolveTaskRole works out what role a user holds on a task. Roles on a parent
+// carry over to its subtasks, and project members who are not collaborators
+// can view and comment on the project's tasks.
+func resolveTaskRole(ctx context.Context, task *models.Task, userID string) (string, error) {
+	current := task
+	for depth := 0; depth < maxRoleDepth; depth++ {
+		if role := current.RoleFor(userID); role != "" {
+			return role, nil
+		}
+		if current.ParentID == "" {
+			break
+		}
+
+		parentID, err := primitive.ObjectIDFromHex(current.ParentID)
+		if err != nil {
+			break
+		}
+		parent, err := getTaskByID(ctx, parentID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			return "", err
+		}
+		current = parent
+	}
+
+	if task.ProjectID != "" {
+		isMember, err := projectRepo.IsMember(ctx, task.ProjectID, userID)
+		if err != nil {
+			return "", err
+		}
+		if isMember {
+			return models.RoleCommenter, nil
+		}
+	}
+	return "", nil
+}
+
+// authorizeTask is the single access-policy check for task and comment handlers.
+// Users with no relation to the task get errTaskNotFound so that task IDs are not
+// leaked; users whose role does not allow the action get errTaskForbidden.
+func authorizeTask(ctx context.Context, taskID primitive.ObjectID, userID string, action models.TaskAction) (*models.Task, error) {
+	task, err := getTaskByID(ctx, taskID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errTaskNotFound
+		}
+		return nil, err
+	}
+	// Trashed tasks are only reachable through the trash endpoints
+	if task.DeletedAt != nil {
+		return nil, errTaskNotFound
+	}
+
+	role, err := resolveTaskRole(ctx, task, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, errTaskNotFound
+	}
+	if !models.RoleAllows(role, action) {
+		return nil, errTaskForbidden
+	}
+	return task, nil
+}
+
+// taskFailure is the error task helpers return when the database lets them
+// down; the client sees message and the cause is only logged
+func taskFailure(message string, err error) error {
+	return &apperrors.AppError{Code: http.StatusInternalServerError, Message: message, InternalErr: err}
+}
+
+// taskErrorResponse maps an error from authorizeTask or one of the task helpers
+// to the message and status reported for it
+func taskErrorResponse(err error) (string, int) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		if appErr.InternalErr != nil {
+			logger.ErrorLogger.Printf("%s: %v", appErr.Message, appErr.InternalErr)
+		}
+		return appErr.Message, appErr.Code
+	}
+
+	switch err {
+	case errInvalidTaskID:
+		return "Invalid task ID", http.StatusBadRequest
+	case errTaskNotFound:
+		return "Task not found or unauthorized", http.StatusNotFound
+	case errTaskForbidden:
+		return "You do not have permission to perform this action on this task", http.StatusForbidden
+	default:
+		logger.ErrorLogger.Printf("Failed to verify task: %v", err)
+		return "Failed to verify task", http.StatusInternalServerError
+	}
+}
+
+// sendTaskAccessError writes the response for an error from authorizeTask or
+// one of the task helpers
+func sendTaskAccessError(w http.ResponseWriter, err error) {
+	message, code := taskErrorResponse(err)
+	utils.SendError(w, message, code)
+}
+
+// visibleTasksFilter matches the tasks a user owns or collaborates on
+func visibleTasksFilter(userID string) bson.M {
+	return bson.M{
+		"$or": []bson.M{
+			{"user_id": userID},
+			{"collaborators.user_id": userID},
+			// Collaborators stored before roles existed are bare IDs
+			{"collaborators": userID},
+		},
+	}
+}
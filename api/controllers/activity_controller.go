@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"api/configs"
+	"api/logger"
+	"api/middleware"
+	"api/models"
+	"api/repositories"
+	"api/utils"
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var activityRepo = repositories.NewActivityRepository(configs.GetCollection(configs.DB, "activities"))
+
+// recordActivity stores an activity event for a task. The audit trail must not
+// undo a mutation that already succeeded, so failures are only logged.
+func recordActivity(ctx context.Context, task *models.Task, actorID, action string, changes []models.FieldChange) {
+	recordCommentActivity(ctx, task, actorID, action, "", changes)
+}
+
+// recordSubtaskCreated records a new subtask in its own feed and in its parent's
+func recordSubtaskCreated(ctx context.Context, parent, subtask *models.Task, actorID string) {
+	recordActivity(ctx, subtask, actorID, models.ActivityTaskCreated, nil)
+	recordActivity(ctx, parent, actorID, models.ActivitySubtaskAdded, []models.FieldChange{
+		{Field: "subtasks." + subtask.ID.Hex(), Before: nil, After: subtask.Title},
+	})
+}
+
+func recordCommentActivity(ctx context.Context, task *models.Task, actorID, action, commentID string, changes []models.FieldChange) {
+	activity := &models.Activity{
+		TaskID:    task.ID.Hex(),
+		TaskTitle: task.Title,
+		ActorID:   actorID,
+		Action:    action,
+		CommentID: commentID,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+	if err := activityRepo.Create(ctx, activity); err != nil {
+		logger.ErrorLogger.Printf("Failed to record %s activity for task %s: %v", action, activity.TaskID, err)
+	}
+}
+
+func sendActivityPage(w http.ResponseWriter, activities []models.Activity, total int64, params utils.PaginationParams) {
+	utils.SendJSON(w, map[string]any{
+		"activity":    activities,
+		"total":       total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": utils.CalculateTotalPages(total, params.Limit),
+	})
+}
+
+// GetTaskActivity returns the audit trail of a single task, newest first
+func GetTaskActivity(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := utils.GetPaginationFromRequest(r)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	activities, total, err := activityRepo.FindPage(ctx, bson.M{"task_id": taskID.Hex()}, params.Page, params.Limit)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch activity", http.StatusInternalServerError)
+		return
+	}
+
+	sendActivityPage(w, activities, total, params)
+}
+
+// GetActivityFeed returns the activity on every task the user owns or
+// collaborates on, plus everything the user did themselves.
+func GetActivityFeed(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := utils.GetPaginationFromRequest(r)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := taskCollection.Find(ctx,
+		visibleTasksFilter(userClaims.ID),
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch tasks", http.StatusInternalServerError)
+		return
+	}
+
+	var tasks []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		utils.SendError(w, "Failed to fetch tasks", http.StatusInternalServerError)
+		return
+	}
+
+	taskIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID.Hex())
+	}
+
+	filter := bson.M{"$or": []bson.M{
+		{"task_id": bson.M{"$in": taskIDs}},
+		{"actor_id": userClaims.ID},
+	}}
+	activities, total, err := activityRepo.FindPage(ctx, filter, params.Page, params.Limit)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch activity", http.StatusInternalServerError)
+		return
+	}
+
+	sendActivityPage(w, activities, total, params)
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// setArchived archives or unarchives a task together with its subtasks
+func setArchived(w http.ResponseWriter, r *http.Request, archive bool) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	updatedTask, err := archiveTask(ctx, task, archive, userClaims.ID)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	utils.SendJSON(w, updatedTask)
+}
+
+// archiveTask archives or unarchives a task with its subtasks. It returns the
+// updated task, or an error for sendTaskAccessError to report.
+func archiveTask(ctx context.Context, task *models.Task, archive bool, actorID string) (*models.Task, error) {
+	if archive == (task.ArchivedAt != nil) {
+		return task, nil
+	}
+
+	subtaskIDs, err := collectSubtaskIDs(ctx, task.ID)
+	if err != nil {
+		return nil, taskFailure("Failed to fetch subtasks", err)
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set":   bson.M{"unarchived_at": now, "updated_at": now},
+		"$unset": bson.M{"archived_at": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	action := models.ActivityTaskUnarchived
+	if archive {
+		update = bson.M{
+			"$set":   bson.M{"archived_at": now, "updated_at": now},
+			"$unset": bson.M{"unarchived_at": ""},
+			"$inc":   bson.M{"version": 1},
+		}
+		action = models.ActivityTaskArchived
+	}
+
+	ids := append([]primitive.ObjectID{task.ID}, subtaskIDs...)
+	if _, err = taskCollection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update); err != nil {
+		return nil, taskFailure("Failed to update task", err)
+	}
+
+	updatedTask, err := getTaskByID(ctx, task.ID)
+	if err != nil {
+		return nil, taskFailure("Failed to fetch updated task", err)
+	}
+
+	recordActivity(ctx, updatedTask, actorID, action, nil)
+	return updatedTask, nil
+}
+
+// ArchiveTask hides a task and its subtasks from lists and statistics without
+// changing their status
+func ArchiveTask(w http.ResponseWriter, r *http.Request) {
+	setArchived(w, r, true)
+}
+
+func UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+	setArchived(w, r, false)
+}
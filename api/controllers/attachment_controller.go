@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"api/configs"
+	"api/middleware"
+	"api/models"
+	"api/repositories"
+	"api/services"
+	"api/utils"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var attachmentService = services.NewAttachmentService(
+	repositories.NewAttachmentRepository(configs.GetCollection(configs.DB, "attachments")),
+	configs.Store,
+)
+
+// multipartOverhead leaves room for form boundaries and headers around the file
+const multipartOverhead = 1 << 20
+
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxAttachmentSize+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		if _, ok := err.(*http.MaxBytesError); ok {
+			utils.SendError(w, fmt.Sprintf("File exceeds the %d MB limit", models.MaxAttachmentSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.SendError(w, "A file is required in the \"file\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	attachment, err := attachmentService.Upload(ctx, taskID.Hex(), userClaims.ID, file, header)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, attachment)
+}
+
+func GetAttachments(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	attachments, err := attachmentService.ListAttachments(ctx, taskID.Hex())
+	if err != nil {
+		utils.SendError(w, "Failed to fetch attachments", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"attachments": attachments,
+		"total":       len(attachments),
+	})
+}
+
+// DownloadAttachment streams the file back with its original name. Files are
+// always served as downloads so uploaded HTML or SVG can't run in the app's origin.
+func DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	attachmentID, err := utils.GetObjectIDFromRequest(r, "attachmentId")
+	if err != nil {
+		utils.SendError(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	attachment, err := attachmentService.GetAttachment(ctx, taskID.Hex(), attachmentID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+	content, err := attachmentService.Open(ctx, attachment)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, content)
+}
+
+// DeleteAttachment lets the uploader remove their own files; the task owner can
+// remove any attachment.
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	attachmentID, err := utils.GetObjectIDFromRequest(r, "attachmentId")
+	if err != nil {
+		utils.SendError(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionView)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	attachment, err := attachmentService.GetAttachment(ctx, taskID.Hex(), attachmentID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if attachment.UploaderID != userClaims.ID && task.UserID != userClaims.ID {
+		utils.SendError(w, "You can only delete your own attachments", http.StatusForbidden)
+		return
+	}
+
+	if err = attachmentService.DeleteAttachment(ctx, attachment); err != nil {
+		utils.SendError(w, "Failed to delete attachment", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
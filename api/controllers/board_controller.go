@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// boardScope matches the tasks on a board: a project's, or the user's own
+// tasks outside any project. Trashed and archived tasks never show.
+func boardScope(userID, projectID string) bson.M {
+	filter := bson.M{
+		"deleted_at":  bson.M{"$exists": false},
+		"archived_at": bson.M{"$exists": false},
+	}
+	if projectID != "" {
+		filter["project_id"] = projectID
+	} else {
+		filter["user_id"] = userID
+		filter["project_id"] = bson.M{"$exists": false}
+	}
+	return filter
+}
+
+// loadColumn returns the tasks of one column in board order. Tasks that have
+// never been placed keep their creation order after the placed ones and get a
+// rank persisted, so later moves have neighbours to rank against.
+func loadColumn(ctx context.Context, scope bson.M, status string) ([]models.Task, error) {
+	filter := bson.M{"status": status}
+	for key, value := range scope {
+		filter[key] = value
+	}
+	cursor, err := taskCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []models.Task{}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return orderColumn(ctx, tasks)
+}
+
+func orderColumn(ctx context.Context, tasks []models.Task) ([]models.Task, error) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if (a.Rank == "") != (b.Rank == "") {
+			return a.Rank != ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+
+	last := ""
+	for i := range tasks {
+		if tasks[i].Rank != "" {
+			last = tasks[i].Rank
+			continue
+		}
+		rank := models.RankBetween(last, "")
+		// Leave the rank alone if someone placed the task in the meantime
+		_, err := taskCollection.UpdateOne(ctx,
+			bson.M{"_id": tasks[i].ID, "rank": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"rank": rank}})
+		if err != nil {
+			return nil, err
+		}
+		tasks[i].Rank = rank
+		last = rank
+	}
+	return tasks, nil
+}
+
+// GetBoard returns the tasks of a board grouped into its workflow's columns
+func GetBoard(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	projectID := r.URL.Query().Get("project_id")
+	if ok := ensureProjectMember(ctx, w, projectID, userClaims.ID); !ok {
+		return
+	}
+
+	workflow, err := workflowService.Resolve(ctx, userClaims.ID, projectID)
+	if err != nil {
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
+		return
+	}
+
+	scope := boardScope(userClaims.ID, projectID)
+	if r.URL.Query().Get("top_level") == "true" {
+		scope["parent_id"] = bson.M{"$exists": false}
+	}
+	cursor, err := taskCollection.Find(ctx, scope)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch tasks", http.StatusInternalServerError)
+		return
+	}
+	var tasks []models.Task
+	if err = cursor.All(ctx, &tasks); err != nil {
+		utils.SendError(w, "Failed to decode tasks", http.StatusInternalServerError)
+		return
+	}
+
+	byStatus := make(map[string][]models.Task)
+	for _, task := range tasks {
+		byStatus[task.Status] = append(byStatus[task.Status], task)
+	}
+
+	board := models.Board{ProjectID: projectID, Workflow: workflow, Columns: []models.BoardColumn{}}
+	statuses := workflow.StatusNames()
+	// Tasks left in a status the workflow no longer has still get a column
+	for status := range byStatus {
+		if !workflow.HasStatus(status) {
+			statuses = append(statuses, status)
+		}
+	}
+	for _, status := range statuses {
+		column, err := orderColumn(ctx, append([]models.Task{}, byStatus[status]...))
+		if err != nil {
+			utils.SendError(w, "Failed to order tasks", http.StatusInternalServerError)
+			return
+		}
+		board.Columns = append(board.Columns, models.BoardColumn{
+			Status: status,
+			Done:   workflow.IsDone(status),
+			Tasks:  column,
+		})
+	}
+
+	utils.SendJSON(w, board)
+}
+
+// MoveTask changes a task's status and its position within the target column
+// in one update. Only the moved task is written.
+func MoveTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var body models.MoveTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Position != nil && *body.Position < 0 {
+		utils.SendError(w, "Position cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	if ok := checkIfMatch(w, r, task); !ok {
+		return
+	}
+	if task.ArchivedAt != nil {
+		utils.SendError(w, "Archived tasks are not on the board", http.StatusConflict)
+		return
+	}
+	if body.Status == "" {
+		body.Status = task.Status
+	}
+
+	// The column as it stands without the moved task
+	scope := boardScope(task.UserID, task.ProjectID)
+	scope["_id"] = bson.M{"$ne": task.ID}
+	column, err := loadColumn(ctx, scope, body.Status)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch column", http.StatusInternalServerError)
+		return
+	}
+
+	position := len(column)
+	if body.Position != nil && *body.Position < position {
+		position = *body.Position
+	}
+	var before, after string
+	if position > 0 {
+		before = column[position-1].Rank
+	}
+	if position < len(column) {
+		after = column[position].Rank
+	}
+	rank := models.RankBetween(before, after)
+
+	updatedTask, err := changeTaskStatus(ctx, task, body.Status, bson.M{"rank": rank}, userClaims.ID)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	setTaskETag(w, updatedTask)
+	utils.SendJSON(w, updatedTask)
+}
@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	apperrors "api/errors"
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkUpdateTasks applies one operation to many tasks in a single request. Each
+// task goes through the same permission and workflow checks as its single-task
+// endpoint and succeeds or fails on its own; the response reports every task.
+func BulkUpdateTasks(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var body models.BulkTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validateBulkRequest(&body); err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	taskIDs, ok := bulkTaskIDs(ctx, w, &body, userClaims.ID)
+	if !ok {
+		return
+	}
+
+	response := models.BulkTaskResponse{
+		Operation: body.Operation,
+		Results:   make([]models.BulkTaskResult, 0, len(taskIDs)),
+	}
+	// Tasks trashed so far, so that subtasks that went with them still count as deleted
+	trashed := map[string]bool{}
+	for _, taskID := range taskIDs {
+		result := applyBulkOperation(ctx, taskID, &body, userClaims.ID, trashed)
+		if result.Success {
+			response.Succeeded++
+			if body.Operation == models.BulkDelete {
+				trashed[taskID] = true
+			}
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	utils.SendJSON(w, response)
+}
+
+// validateBulkRequest rejects a malformed request before any task is touched
+func validateBulkRequest(body *models.BulkTaskRequest) error {
+	if (len(body.TaskIDs) > 0) == (body.Filter != nil) {
+		return fmt.Errorf("provide either task_ids or filter")
+	}
+	if len(body.TaskIDs) > models.MaxBulkTasks {
+		return fmt.Errorf("at most %d tasks can be changed at once", models.MaxBulkTasks)
+	}
+
+	switch body.Operation {
+	case models.BulkSetStatus:
+		if body.Status == "" {
+			return fmt.Errorf("status is required")
+		}
+	case models.BulkSetPriority:
+		task := models.Task{Priority: body.Priority}
+		if err := task.ValidateFields(nil, "priority"); err != nil {
+			return err
+		}
+	case models.BulkAddTags, models.BulkRemoveTags:
+		if len(body.Tags) == 0 {
+			return fmt.Errorf("tags are required")
+		}
+	case models.BulkShiftDueDate:
+		if body.ShiftDays == 0 {
+			return fmt.Errorf("shift_days is required")
+		}
+	case models.BulkArchive:
+	case models.BulkDelete:
+		if body.Subtasks != "" && body.Subtasks != "delete" && body.Subtasks != "detach" {
+			return fmt.Errorf("subtasks must be delete or detach")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q", body.Operation)
+	}
+	return nil
+}
+
+// bulkTaskIDs returns the tasks a bulk request targets: the listed IDs, or the
+// tasks the filter matches among those the user can list
+func bulkTaskIDs(ctx context.Context, w http.ResponseWriter, body *models.BulkTaskRequest, userID string) ([]string, bool) {
+	if body.Filter == nil {
+		taskIDs := make([]string, 0, len(body.TaskIDs))
+		for _, taskID := range body.TaskIDs {
+			if !slices.Contains(taskIDs, taskID) {
+				taskIDs = append(taskIDs, taskID)
+			}
+		}
+		return taskIDs, true
+	}
+
+	params := utils.PaginationParams{
+		Search:   body.Filter.Search,
+		Priority: body.Filter.Priority,
+		Status:   body.Filter.Status,
+		TopLevel: body.Filter.TopLevel,
+		Archived: body.Filter.Archived,
+	}
+	dateRange := &utils.DateRange{StartDate: body.Filter.StartDate, EndDate: body.Filter.EndDate}
+	filter, ok := taskListFilter(ctx, w, userID, body.Filter.ProjectID, params, dateRange)
+	if !ok {
+		return nil, false
+	}
+
+	cursor, err := taskCollection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.M{"created_at": 1}).
+		SetLimit(models.MaxBulkTasks+1))
+	if err != nil {
+		utils.SendError(w, "Failed to fetch tasks", http.StatusInternalServerError)
+		return nil, false
+	}
+	var matches []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &matches); err != nil {
+		utils.SendError(w, "Failed to fetch tasks", http.StatusInternalServerError)
+		return nil, false
+	}
+	if len(matches) > models.MaxBulkTasks {
+		utils.SendError(w, fmt.Sprintf("Filter matches more than %d tasks; narrow it down", models.MaxBulkTasks), http.StatusBadRequest)
+		return nil, false
+	}
+
+	taskIDs := make([]string, 0, len(matches))
+	for _, match := range matches {
+		taskIDs = append(taskIDs, match.ID.Hex())
+	}
+	return taskIDs, true
+}
+
+// applyBulkOperation runs the operation on one task and reports the outcome
+// with the status and message its single-task endpoint would have sent
+func applyBulkOperation(ctx context.Context, taskID string, body *models.BulkTaskRequest, actorID string, trashed map[string]bool) models.BulkTaskResult {
+	result := models.BulkTaskResult{TaskID: taskID, Success: true, Code: http.StatusOK}
+	if err := runBulkOperation(ctx, taskID, body, actorID, trashed); err != nil {
+		result.Success = false
+		result.Error, result.Code = taskErrorResponse(err)
+	}
+	return result
+}
+
+func runBulkOperation(ctx context.Context, id string, body *models.BulkTaskRequest, actorID string, trashed map[string]bool) error {
+	taskID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errInvalidTaskID
+	}
+
+	// Deleting is reserved for the owner, as with DELETE /api/tasks/{id}
+	action := models.ActionEdit
+	if body.Operation == models.BulkDelete {
+		action = models.ActionManage
+	}
+	task, err := authorizeTask(ctx, taskID, actorID, action)
+	if err == errTaskNotFound && body.Operation == models.BulkDelete {
+		// A subtask listed after its parent is already in the trash with it
+		if deleted, findErr := findTrashedTask(ctx, taskID, actorID); findErr == nil && trashed[deleted.TrashedWith] {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch body.Operation {
+	case models.BulkSetStatus:
+		if body.Status == task.Status {
+			return nil
+		}
+		_, err = changeTaskStatus(ctx, task, body.Status, nil, actorID)
+		return err
+	case models.BulkArchive:
+		_, err = archiveTask(ctx, task, true, actorID)
+		return err
+	case models.BulkDelete:
+		return trashTask(ctx, task, body.Subtasks, actorID)
+	}
+
+	changed := *task
+	var field string
+	switch body.Operation {
+	case models.BulkSetPriority:
+		changed.Priority, field = body.Priority, "priority"
+	case models.BulkAddTags:
+		changed.Tags, field = append([]string{}, task.Tags...), "tags"
+		for _, tag := range body.Tags {
+			if !slices.Contains(changed.Tags, tag) {
+				changed.Tags = append(changed.Tags, tag)
+			}
+		}
+	case models.BulkRemoveTags:
+		changed.Tags, field = []string{}, "tags"
+		for _, tag := range task.Tags {
+			if !slices.Contains(body.Tags, tag) {
+				changed.Tags = append(changed.Tags, tag)
+			}
+		}
+	case models.BulkShiftDueDate:
+		changed.DueDate, field = task.DueDate.AddDate(0, 0, body.ShiftDays), "due_date"
+	}
+	if changed.Priority == task.Priority && slices.Equal(changed.Tags, task.Tags) && changed.DueDate.Equal(task.DueDate) {
+		return nil
+	}
+
+	workflow, err := taskWorkflow(ctx, task)
+	if err != nil {
+		return taskFailure("Failed to load workflow", err)
+	}
+	if err = changed.ValidateFields(workflow, field); err != nil {
+		return apperrors.NewValidationError(err.Error(), nil)
+	}
+	changed.UpdatedAt = time.Now()
+
+	_, err = saveTaskChanges(ctx, task, &changed, []string{field}, workflow, actorID)
+	return err
+}
@@ -2,21 +2,97 @@ package controllers
 
 import (
 	"api/configs"
+	"api/logger"
 	"api/middleware"
 	"api/models"
+	"api/utils"
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var commentCollection = configs.GetCollection(configs.DB, "comments")
 
+// authorizeCommentTask applies the task access policy to the task in the comment route
+func authorizeCommentTask(r *http.Request, userID string, action models.TaskAction) (*models.Task, error) {
+	taskID, err := primitive.ObjectIDFromHex(mux.Vars(r)["taskId"])
+	if err != nil {
+		return nil, errInvalidTaskID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return authorizeTask(ctx, taskID, userID, action)
+}
+
+// findTaskComment loads a comment, making sure it belongs to the task in the route
+func findTaskComment(ctx context.Context, commentID primitive.ObjectID, taskID string) (*models.Comment, error) {
+	var comment models.Comment
+	err := commentCollection.FindOne(ctx, bson.M{"_id": commentID, "task_id": taskID}).Decode(&comment)
+	return &comment, err
+}
+
+// sendCommentLookupError maps a findTaskComment error to an HTTP response
+func sendCommentLookupError(w http.ResponseWriter, err error) {
+	if err == mongo.ErrNoDocuments {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Comment not found"})
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch comment"})
+}
+
+// resolveMentions maps the @handles in a comment to users on the task, leaving out the author
+func resolveMentions(ctx context.Context, task *models.Task, comment *models.Comment) ([]models.User, error) {
+	handles := comment.MentionHandles()
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	participantIDs := []string{task.UserID}
+	for _, collaborator := range task.Collaborators {
+		participantIDs = append(participantIDs, collaborator.UserID)
+	}
+	participants, err := userRepo.FindByIDs(ctx, toObjectIDs(participantIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	mentioned := make([]models.User, 0)
+	for _, user := range participants {
+		if user.ID.Hex() == comment.UserID {
+			continue
+		}
+		email := strings.ToLower(user.Email)
+		localPart := strings.SplitN(email, "@", 2)[0]
+		name := strings.ToLower(strings.ReplaceAll(user.Name, " ", ""))
+		for _, handle := range handles {
+			if handle == email || handle == localPart || handle == name {
+				mentioned = append(mentioned, user)
+				break
+			}
+		}
+	}
+	return mentioned, nil
+}
+
+func mentionIDs(users []models.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID.Hex())
+	}
+	return ids
+}
+
 func AddComment(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -24,6 +100,12 @@ func AddComment(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	taskID := params["taskId"]
 
+	task, err := authorizeCommentTask(r, userClaims.ID, models.ActionComment)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
 	var comment models.Comment
 	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -31,8 +113,15 @@ func AddComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Everything but the content and the comment replied to is set by the server
+	comment.ID = primitive.NilObjectID
 	comment.TaskID = taskID
 	comment.UserID = userClaims.ID
+	comment.ThreadID = ""
+	comment.Reactions = nil
+	comment.EditedAt = nil
+	comment.DeletedAt = nil
+	comment.DeletedBy = ""
 	comment.CreatedAt = time.Now()
 	comment.UpdatedAt = time.Now()
 
@@ -42,7 +131,42 @@ func AddComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := commentCollection.InsertOne(context.Background(), comment)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Replies join the thread of the comment they answer
+	if comment.ParentID != "" {
+		parentID, err := primitive.ObjectIDFromHex(comment.ParentID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid parent comment ID"})
+			return
+		}
+		parent, err := findTaskComment(ctx, parentID, taskID)
+		if err != nil {
+			sendCommentLookupError(w, err)
+			return
+		}
+		if parent.IsDeleted() {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Cannot reply to a deleted comment"})
+			return
+		}
+		comment.ThreadID = parent.ThreadID
+		if comment.ThreadID == "" {
+			comment.ThreadID = parent.ID.Hex()
+		}
+	}
+
+	mentioned, err := resolveMentions(ctx, task, &comment)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to resolve mentions"})
+		return
+	}
+	comment.Mentions = mentionIDs(mentioned)
+
+	result, err := commentCollection.InsertOne(ctx, comment)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to add comment"})
@@ -50,31 +174,101 @@ func AddComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	comment.ID = result.InsertedID.(primitive.ObjectID)
+	recordCommentActivity(ctx, task, userClaims.ID, models.ActivityCommentAdded, comment.ID.Hex(), nil)
+	notifyMentions(task, &comment, mentioned)
+	notifyComment(task, &comment, mentioned)
 	json.NewEncoder(w).Encode(comment)
 }
 
+// GetComments returns a page of top-level comments, oldest first unless
+// sort_dir=desc, each with its replies in chronological order.
 func GetComments(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
 	params := mux.Vars(r)
 	taskID := params["taskId"]
 
-	cursor, err := commentCollection.Find(context.Background(), bson.M{"task_id": taskID})
+	if _, err := authorizeCommentTask(r, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	pagination := utils.GetPaginationFromRequest(r)
+	sortDirection := 1
+	if pagination.SortDir == "desc" {
+		sortDirection = -1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rootFilter := bson.M{"task_id": taskID, "parent_id": bson.M{"$exists": false}}
+	total, err := commentCollection.CountDocuments(ctx, rootFilter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch comments"})
+		return
+	}
+
+	cursor, err := commentCollection.Find(ctx, rootFilter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: sortDirection}, {Key: "_id", Value: sortDirection}}).
+		SetSkip((pagination.Page-1)*pagination.Limit).
+		SetLimit(pagination.Limit))
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch comments"})
 		return
 	}
 
-	var comments []models.Comment
-	if err = cursor.All(context.Background(), &comments); err != nil {
+	var roots []models.Comment
+	if err = cursor.All(ctx, &roots); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to decode comments"})
 		return
 	}
 
+	threads := make([]models.CommentThread, 0, len(roots))
+	threadIndex := make(map[string]int, len(roots))
+	for i, root := range roots {
+		threads = append(threads, models.CommentThread{Comment: root, Replies: make([]models.Comment, 0)})
+		threadIndex[root.ID.Hex()] = i
+	}
+
+	if len(roots) > 0 {
+		threadIDs := make([]string, 0, len(roots))
+		for id := range threadIndex {
+			threadIDs = append(threadIDs, id)
+		}
+
+		cursor, err = commentCollection.Find(ctx,
+			bson.M{"task_id": taskID, "thread_id": bson.M{"$in": threadIDs}},
+			options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}),
+		)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to fetch replies"})
+			return
+		}
+
+		var replies []models.Comment
+		if err = cursor.All(ctx, &replies); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to decode replies"})
+			return
+		}
+		for _, reply := range replies {
+			i := threadIndex[reply.ThreadID]
+			threads[i].Replies = append(threads[i].Replies, reply)
+		}
+	}
+
 	json.NewEncoder(w).Encode(map[string]any{
-		"comments": comments,
+		"comments":    threads,
+		"total":       total,
+		"page":        pagination.Page,
+		"limit":       pagination.Limit,
+		"total_pages": utils.CalculateTotalPages(total, pagination.Limit),
 	})
 }
 
@@ -90,6 +284,28 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	task, err := authorizeCommentTask(r, userClaims.ID, models.ActionComment)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	existing, err := findTaskComment(context.Background(), commentID, params["taskId"])
+	if err != nil {
+		sendCommentLookupError(w, err)
+		return
+	}
+	if existing.UserID != userClaims.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "You can only edit your own comments"})
+		return
+	}
+	if existing.IsDeleted() {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Deleted comments cannot be edited"})
+		return
+	}
+
 	var updateComment models.Comment
 	if err = json.NewDecoder(r.Body).Decode(&updateComment); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -103,17 +319,36 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Ensure the comment belongs to the user
+	updateComment.UserID = userClaims.ID
+	mentioned, err := resolveMentions(context.Background(), task, &updateComment)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to resolve mentions"})
+		return
+	}
+
+	// Ensure the comment belongs to the user and hasn't changed since it was read
 	filter := bson.M{
-		"_id":     commentID,
-		"user_id": userClaims.ID,
+		"_id":        commentID,
+		"user_id":    userClaims.ID,
+		"content":    existing.Content,
+		"deleted_at": bson.M{"$exists": false},
 	}
 
+	// The content being replaced is kept as a revision
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
 			"content":    updateComment.Content,
-			"updated_at": time.Now(),
+			"mentions":   mentionIDs(mentioned),
+			"edited_at":  now,
+			"updated_at": now,
 		},
+		"$push": bson.M{"revisions": models.CommentRevision{
+			Content:  existing.Content,
+			EditedBy: userClaims.ID,
+			EditedAt: now,
+		}},
 	}
 
 	// Return the updated document
@@ -128,11 +363,33 @@ func UpdateComment(w http.ResponseWriter, r *http.Request) {
 	).Decode(&updatedComment)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Comment was changed or deleted, please retry"})
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update comment"})
 		return
 	}
 
+	// Only people mentioned for the first time in this edit are notified
+	alreadyMentioned := make(map[string]bool, len(existing.Mentions))
+	for _, id := range existing.Mentions {
+		alreadyMentioned[id] = true
+	}
+	newlyMentioned := make([]models.User, 0, len(mentioned))
+	for _, user := range mentioned {
+		if !alreadyMentioned[user.ID.Hex()] {
+			newlyMentioned = append(newlyMentioned, user)
+		}
+	}
+	notifyMentions(task, &updatedComment, newlyMentioned)
+
+	recordCommentActivity(context.Background(), task, userClaims.ID, models.ActivityCommentEdited, commentID.Hex(), []models.FieldChange{
+		{Field: "content", Before: existing.Content, After: updatedComment.Content},
+	})
+
 	json.NewEncoder(w).Encode(updatedComment)
 }
 
@@ -149,10 +406,50 @@ func DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify comment ownership
-	result, err := commentCollection.DeleteOne(context.Background(), bson.M{
-		"_id":     commentID,
-		"user_id": userClaims.ID,
+	task, err := authorizeCommentTask(r, userClaims.ID, models.ActionComment)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	existing, err := findTaskComment(context.Background(), commentID, params["taskId"])
+	if err != nil {
+		sendCommentLookupError(w, err)
+		return
+	}
+
+	// Authors delete their own comments; the task owner can moderate any of them
+	if existing.UserID != userClaims.ID && task.UserID != userClaims.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "You can only delete your own comments"})
+		return
+	}
+
+	if existing.IsDeleted() {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Leave a tombstone so replies keep their place in the thread; the
+	// removed text stays in the revision history for auditing.
+	now := time.Now()
+	_, err = commentCollection.UpdateOne(context.Background(), bson.M{
+		"_id":        commentID,
+		"task_id":    params["taskId"],
+		"deleted_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"content":    models.DeletedCommentContent,
+			"deleted_at": now,
+			"deleted_by": userClaims.ID,
+			"updated_at": now,
+		},
+		"$unset": bson.M{"mentions": "", "reactions": ""},
+		"$push": bson.M{"revisions": models.CommentRevision{
+			Content:  existing.Content,
+			EditedBy: userClaims.ID,
+			EditedAt: now,
+		}},
 	})
 
 	if err != nil {
@@ -161,11 +458,169 @@ func DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result.DeletedCount == 0 {
+	// The deleted text stays out of the activity log, which every viewer of the
+	// task can read; GetCommentRevisions decides who may still see it
+	if err = activityRepo.RedactComment(context.Background(), commentID.Hex()); err != nil {
+		logger.ErrorLogger.Printf("Failed to redact activity of comment %s: %v", commentID.Hex(), err)
+	}
+	recordCommentActivity(context.Background(), task, userClaims.ID, models.ActivityCommentDeleted, commentID.Hex(), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func AddReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := mux.Vars(r)
+	commentID, err := primitive.ObjectIDFromHex(params["commentId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	if _, err = authorizeCommentTask(r, userClaims.ID, models.ActionComment); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	var request models.ReactionRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if err = models.ValidateEmoji(request.Emoji); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	existing, err := findTaskComment(ctx, commentID, params["taskId"])
+	if err != nil {
+		sendCommentLookupError(w, err)
+		return
+	}
+	if existing.IsDeleted() {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Cannot react to a deleted comment"})
+		return
+	}
+
+	// Each user can react with a given emoji once
+	_, err = commentCollection.UpdateOne(ctx, bson.M{
+		"_id": commentID,
+		"reactions": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"emoji":   request.Emoji,
+			"user_id": userClaims.ID,
+		}}},
+	}, bson.M{
+		"$push": bson.M{"reactions": models.Reaction{
+			Emoji:     request.Emoji,
+			UserID:    userClaims.ID,
+			CreatedAt: time.Now(),
+		}},
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to add reaction"})
+		return
+	}
+
+	comment, err := findTaskComment(ctx, commentID, params["taskId"])
+	if err != nil {
+		sendCommentLookupError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(comment)
+}
+
+func RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := mux.Vars(r)
+	commentID, err := primitive.ObjectIDFromHex(params["commentId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	if _, err = authorizeCommentTask(r, userClaims.ID, models.ActionComment); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := commentCollection.UpdateOne(ctx, bson.M{
+		"_id":     commentID,
+		"task_id": params["taskId"],
+	}, bson.M{
+		"$pull": bson.M{"reactions": bson.M{
+			"emoji":   params["emoji"],
+			"user_id": userClaims.ID,
+		}},
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to remove reaction"})
+		return
+	}
+	if result.MatchedCount == 0 {
 		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Comment not found or unauthorized"})
+		json.NewEncoder(w).Encode(map[string]string{"error": "Comment not found"})
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GetCommentRevisions lists the past versions of a comment, oldest first. The
+// history of a deleted comment is only shown to its author and the task owner.
+func GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := mux.Vars(r)
+	commentID, err := primitive.ObjectIDFromHex(params["commentId"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid comment ID"})
+		return
+	}
+
+	task, err := authorizeCommentTask(r, userClaims.ID, models.ActionView)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	comment, err := findTaskComment(context.Background(), commentID, params["taskId"])
+	if err != nil {
+		sendCommentLookupError(w, err)
+		return
+	}
+	if comment.IsDeleted() && comment.UserID != userClaims.ID && task.UserID != userClaims.ID {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "You cannot view the history of a deleted comment"})
+		return
+	}
+
+	revisions := comment.Revisions
+	if revisions == nil {
+		revisions = make([]models.CommentRevision, 0)
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"comment_id": comment.ID,
+		"current":    comment.Content,
+		"revisions":  revisions,
+	})
+}
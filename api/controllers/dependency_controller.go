@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	apperrors "api/errors"
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxGraphNodes bounds how much of the dependency graph a single request walks
+const maxGraphNodes = 200
+
+// findOpenBlockers returns the blockers of a task that are not done yet. Each
+// blocker is judged by its own workflow, which may differ from the task's.
+func findOpenBlockers(ctx context.Context, task *models.Task) ([]models.Task, error) {
+	open := make([]models.Task, 0)
+	if len(task.BlockedBy) == 0 {
+		return open, nil
+	}
+
+	ids := toObjectIDs(task.BlockedBy)
+	// Trashed blockers no longer hold anything up
+	cursor, err := taskCollection.Find(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"deleted_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var blockers []models.Task
+	if err = cursor.All(ctx, &blockers); err != nil {
+		return nil, err
+	}
+
+	for _, blocker := range blockers {
+		workflow, err := taskWorkflow(ctx, &blocker)
+		if err != nil {
+			return nil, err
+		}
+		if !workflow.IsDone(blocker.Status) {
+			open = append(open, blocker)
+		}
+	}
+	return open, nil
+}
+
+// checkNotBlocked rejects starting or completing a task while a blocker is still open
+func checkNotBlocked(ctx context.Context, task *models.Task, status string, workflow *models.Workflow) error {
+	if !workflow.IsActive(status) && !workflow.IsDone(status) {
+		return nil
+	}
+
+	blockers, err := findOpenBlockers(ctx, task)
+	if err != nil {
+		return taskFailure("Failed to check dependencies", err)
+	}
+	if len(blockers) > 0 {
+		titles := make([]string, 0, len(blockers))
+		for _, blocker := range blockers {
+			titles = append(titles, blocker.Title)
+		}
+		return apperrors.NewConflictError(fmt.Sprintf("Task is blocked by: %s", strings.Join(titles, ", ")))
+	}
+	return nil
+}
+
+// createsCycle reports whether making blockerID block taskID would close a loop,
+// i.e. whether blockerID already depends on taskID directly or transitively.
+func createsCycle(ctx context.Context, taskID, blockerID string) (bool, error) {
+	visited := map[string]bool{blockerID: true}
+	queue := []string{blockerID}
+
+	for len(queue) > 0 {
+		cursor, err := taskCollection.Find(ctx,
+			bson.M{"_id": bson.M{"$in": toObjectIDs(queue)}},
+			options.Find().SetProjection(bson.M{"blocked_by": 1}),
+		)
+		if err != nil {
+			return false, err
+		}
+
+		var tasks []models.Task
+		if err = cursor.All(ctx, &tasks); err != nil {
+			return false, err
+		}
+
+		queue = queue[:0]
+		for _, task := range tasks {
+			for _, id := range task.BlockedBy {
+				if id == taskID {
+					return true, nil
+				}
+				if !visited[id] {
+					visited[id] = true
+					queue = append(queue, id)
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// removeDependencyEdges drops every edge that points at one of the given tasks
+func removeDependencyEdges(ctx context.Context, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	_, err := taskCollection.UpdateMany(ctx,
+		bson.M{"$or": []bson.M{
+			{"blocked_by": bson.M{"$in": taskIDs}},
+			{"blocks": bson.M{"$in": taskIDs}},
+		}},
+		bson.M{
+			"$pull": bson.M{
+				"blocked_by": bson.M{"$in": taskIDs},
+				"blocks":     bson.M{"$in": taskIDs},
+			},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	return err
+}
+
+func toObjectIDs(hexIDs []string) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hex := range hexIDs {
+		if id, err := primitive.ObjectIDFromHex(hex); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func AddDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		BlockedBy string `json:"blocked_by"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	blockerID, err := primitive.ObjectIDFromHex(request.BlockedBy)
+	if err != nil {
+		utils.SendError(w, "Invalid blocker task ID", http.StatusBadRequest)
+		return
+	}
+	if blockerID == taskID {
+		utils.SendError(w, "A task cannot block itself", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The user must be able to edit both ends of the edge
+	for _, id := range []primitive.ObjectID{taskID, blockerID} {
+		if _, err = authorizeTask(ctx, id, userClaims.ID, models.ActionEdit); err != nil {
+			sendTaskAccessError(w, err)
+			return
+		}
+	}
+
+	cycle, err := createsCycle(ctx, taskID.Hex(), blockerID.Hex())
+	if err != nil {
+		utils.SendError(w, "Failed to check dependencies", http.StatusInternalServerError)
+		return
+	}
+	if cycle {
+		utils.SendError(w, "Dependency would create a cycle", http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	if _, err = taskCollection.UpdateOne(ctx, bson.M{"_id": taskID}, bson.M{
+		"$addToSet": bson.M{"blocked_by": blockerID.Hex()},
+		"$set":      bson.M{"updated_at": now},
+		"$inc":      bson.M{"version": 1},
+	}); err != nil {
+		utils.SendError(w, "Failed to add dependency", http.StatusInternalServerError)
+		return
+	}
+	if _, err = taskCollection.UpdateOne(ctx, bson.M{"_id": blockerID}, bson.M{
+		"$addToSet": bson.M{"blocks": taskID.Hex()},
+		"$set":      bson.M{"updated_at": now},
+		"$inc":      bson.M{"version": 1},
+	}); err != nil {
+		utils.SendError(w, "Failed to add dependency", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{"message": "Dependency added successfully"})
+}
+
+func RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	blockerID, err := utils.GetObjectIDFromRequest(r, "blockerId")
+	if err != nil {
+		utils.SendError(w, "Invalid blocker task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	now := time.Now()
+	result, err := taskCollection.UpdateOne(ctx, bson.M{"_id": taskID, "blocked_by": blockerID.Hex()}, bson.M{
+		"$pull": bson.M{"blocked_by": blockerID.Hex()},
+		"$set":  bson.M{"updated_at": now},
+		"$inc":  bson.M{"version": 1},
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to remove dependency", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		utils.SendError(w, "Dependency not found", http.StatusNotFound)
+		return
+	}
+	if _, err = taskCollection.UpdateOne(ctx, bson.M{"_id": blockerID}, bson.M{
+		"$pull": bson.M{"blocks": taskID.Hex()},
+		"$set":  bson.M{"updated_at": now},
+		"$inc":  bson.M{"version": 1},
+	}); err != nil {
+		utils.SendError(w, "Failed to remove dependency", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDependencyGraph returns every task connected to the given one through
+// blocked_by/blocks edges, in both directions. Tasks the user can't view appear
+// by ID only, and the walk doesn't continue past them.
+func GetDependencyGraph(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	root, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionView)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	graph := models.DependencyGraph{
+		Nodes: []models.Task{*root},
+		Edges: make([]models.TaskDependency, 0),
+	}
+	visited := map[string]bool{root.ID.Hex(): true}
+	frontier := []models.Task{*root}
+
+	for len(frontier) > 0 && len(graph.Nodes) < maxGraphNodes {
+		var next []string
+		for _, task := range frontier {
+			id := task.ID.Hex()
+			// Each edge is recorded from its blocked side only, so it appears once
+			for _, blocker := range task.BlockedBy {
+				graph.Edges = append(graph.Edges, models.TaskDependency{From: blocker, To: id})
+				if !visited[blocker] {
+					visited[blocker] = true
+					next = append(next, blocker)
+				}
+			}
+			for _, blocked := range task.Blocks {
+				if !visited[blocked] {
+					visited[blocked] = true
+					next = append(next, blocked)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+
+		cursor, err := taskCollection.Find(ctx, bson.M{
+			"_id":        bson.M{"$in": toObjectIDs(next)},
+			"deleted_at": bson.M{"$exists": false},
+		})
+		if err != nil {
+			utils.SendError(w, "Failed to fetch dependencies", http.StatusInternalServerError)
+			return
+		}
+		var reached []models.Task
+		if err = cursor.All(ctx, &reached); err != nil {
+			utils.SendError(w, "Failed to decode dependencies", http.StatusInternalServerError)
+			return
+		}
+
+		frontier = frontier[:0]
+		for _, task := range reached {
+			visible, err := authorizeTask(ctx, task.ID, userClaims.ID, models.ActionView)
+			if err == errTaskNotFound || err == errTaskForbidden {
+				graph.Nodes = append(graph.Nodes, models.Task{ID: task.ID})
+				continue
+			}
+			if err != nil {
+				sendTaskAccessError(w, err)
+				return
+			}
+			graph.Nodes = append(graph.Nodes, *visible)
+			frontier = append(frontier, *visible)
+		}
+	}
+
+	utils.SendJSON(w, graph)
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	apperrors "api/errors"
+	"api/models"
+	"api/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// taskETag identifies one version of a task for conditional requests
+func taskETag(task *models.Task) string {
+	return fmt.Sprintf(`"%s-%d"`, task.ID.Hex(), task.Version)
+}
+
+func setTaskETag(w http.ResponseWriter, task *models.Task) {
+	w.Header().Set("ETag", taskETag(task))
+}
+
+// etagMatches reports whether a list of entity tags from If-Match or
+// If-None-Match names the task's current version. If-Match needs the strong
+// comparison, so weak tags only count for If-None-Match.
+func etagMatches(header string, task *models.Task, weak bool) bool {
+	current := taskETag(task)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch rejects a write whose If-Match header names another version of
+// the task. Requests without the header are not conditional.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, task *models.Task) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" || etagMatches(header, task, false) {
+		return true
+	}
+	setTaskETag(w, task)
+	sendVersionConflict(w)
+	return false
+}
+
+// errVersionConflict is returned by writes based on a stale version of a task
+var errVersionConflict = &apperrors.AppError{
+	Code:    http.StatusPreconditionFailed,
+	Message: "Task was changed by someone else; fetch it again and retry",
+}
+
+func sendVersionConflict(w http.ResponseWriter) {
+	utils.SendError(w, errVersionConflict.Message, errVersionConflict.Code)
+}
+
+// versionFilter matches the task only while it is still at the given version,
+// so a write based on a stale read changes nothing. Tasks stored before
+// versions existed have no version field, which counts as 0.
+func versionFilter(taskID primitive.ObjectID, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": taskID, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": taskID, "version": version}
+}
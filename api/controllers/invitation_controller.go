@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	apperrors "api/errors"
+	"api/middleware"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxInviteAcceptAttempts bounds how often accepting an invitation re-reads a
+// task that keeps changing underneath it
+const maxInviteAcceptAttempts = 3
+
+type InvitationController struct {
+	service *services.InvitationService
+}
+
+func NewInvitationController(service *services.InvitationService) *InvitationController {
+	return &InvitationController{service: service}
+}
+
+func (c *InvitationController) InviteCollaborator(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var request models.InvitationRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Only the owner invites collaborators
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionManage)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	invitation, err := c.service.Invite(ctx, task, userClaims.ID, request)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, invitation)
+}
+
+func (c *InvitationController) GetTaskInvitations(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionManage); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	invitations, err := c.service.ListTaskInvitations(ctx, taskID.Hex())
+	if err != nil {
+		utils.SendError(w, "Failed to fetch invitations", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{"invitations": invitations})
+}
+
+func (c *InvitationController) GetMyInvitations(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	invitations, err := c.service.ListPendingInvitations(r.Context(), userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch invitations", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{"invitations": invitations})
+}
+
+func (c *InvitationController) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	c.respond(w, r, true)
+}
+
+func (c *InvitationController) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	c.respond(w, r, false)
+}
+
+func (c *InvitationController) respond(w http.ResponseWriter, r *http.Request, accept bool) {
+	invitationID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid invitation ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	if err := c.service.Respond(r.Context(), invitationID, userClaims.ID, accept, addInvitedCollaborator); err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if accept {
+		utils.SendJSON(w, map[string]string{"message": "Invitation accepted"})
+		return
+	}
+	utils.SendJSON(w, map[string]string{"message": "Invitation declined"})
+}
+
+// ConfirmInvitation answers a click on the accept/decline links sent by email.
+// A GET must not change anything, since mail scanners and link previews follow
+// links, so it only shows a button that sends the POST RespondToInvitation acts on.
+func (c *InvitationController) ConfirmInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.SendError(w, "Invitation token is required", http.StatusBadRequest)
+		return
+	}
+	action := r.URL.Query().Get("action")
+	if action != "accept" && action != "decline" {
+		utils.SendError(w, "action must be accept or decline", http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := c.service.InvitationForToken(r.Context(), token)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	question := fmt.Sprintf("Join <strong>%s</strong> as a %s?", html.EscapeString(invitation.TaskTitle), invitation.Role)
+	button := "Accept invitation"
+	if action == "decline" {
+		question = fmt.Sprintf("Decline the invitation to <strong>%s</strong>?", html.EscapeString(invitation.TaskTitle))
+		button = "Decline invitation"
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body>
+	<p>%s</p>
+	<form method="POST" action="?token=%s&amp;action=%s">
+		<button type="submit">%s</button>
+	</form>
+</body>
+</html>
+`, question, url.QueryEscape(token), action, button)
+}
+
+// RespondToInvitation accepts or declines the invitation a signed email link
+// was made for; ConfirmInvitation's page posts here
+func (c *InvitationController) RespondToInvitation(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.SendError(w, "Invitation token is required", http.StatusBadRequest)
+		return
+	}
+
+	var accept bool
+	switch r.URL.Query().Get("action") {
+	case "accept":
+		accept = true
+	case "decline":
+		accept = false
+	default:
+		utils.SendError(w, "action must be accept or decline", http.StatusBadRequest)
+		return
+	}
+
+	status, err := c.service.RespondWithToken(r.Context(), token, accept, addInvitedCollaborator)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	message := "Invitation " + status
+	if status == models.InvitationPending {
+		message = "Sign up with the invited email address to join the task"
+	}
+	utils.SendJSON(w, map[string]string{"status": status, "message": message})
+}
+
+// addInvitedCollaborator puts the invitee on the task through setCollaborator,
+// so the change is versioned, recorded and notified like any other. The
+// inviter counts as the one adding them.
+func addInvitedCollaborator(ctx context.Context, invitation *models.Invitation, userID string) error {
+	taskID, err := primitive.ObjectIDFromHex(invitation.TaskID)
+	if err != nil {
+		return apperrors.NewNotFoundError("Task", invitation.TaskID)
+	}
+
+	for attempt := 0; attempt < maxInviteAcceptAttempts; attempt++ {
+		task, err := getTaskByID(ctx, taskID)
+		if err == mongo.ErrNoDocuments || (err == nil && task.DeletedAt != nil) {
+			return apperrors.NewNotFoundError("Task", invitation.TaskID)
+		}
+		if err != nil {
+			return err
+		}
+		if task.UserID == userID {
+			return apperrors.NewValidationError("The task owner cannot be a collaborator", nil)
+		}
+
+		// Someone else changed the task since it was read; read it again
+		if err = setCollaborator(ctx, task, userID, invitation.Role, invitation.InviterID); err != errVersionConflict {
+			return err
+		}
+	}
+	return errVersionConflict
+}
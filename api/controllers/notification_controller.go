@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/services"
+	"api/utils"
+	"context"
+	"net/http"
+	"time"
+)
+
+type NotificationController struct {
+	service *services.NotificationService
+}
+
+func NewNotificationController(service *services.NotificationService) *NotificationController {
+	return &NotificationController{service: service}
+}
+
+// GetNotifications lists the user's notifications, newest first. Pass
+// ?unread=true to only get the ones not read yet.
+func (c *NotificationController) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := utils.GetPaginationFromRequest(r)
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notifications, total, err := c.service.List(ctx, userClaims.ID, unreadOnly, params.Page, params.Limit)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+	unread, err := c.service.UnreadCount(ctx, userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to count unread notifications", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"notifications": notifications,
+		"unread":        unread,
+		"total":         total,
+		"page":          params.Page,
+		"limit":         params.Limit,
+		"total_pages":   utils.CalculateTotalPages(total, params.Limit),
+	})
+}
+
+func (c *NotificationController) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	unread, err := c.service.UnreadCount(ctx, userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to count unread notifications", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]int64{"unread": unread})
+}
+
+func (c *NotificationController) MarkRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notification, err := c.service.MarkRead(ctx, notificationID, userClaims.ID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, notification)
+}
+
+func (c *NotificationController) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updated, err := c.service.MarkAllRead(ctx, userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to mark notifications as read", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]int64{"updated": updated})
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"api/logger"
+	"api/models"
+	"api/notify"
+	"context"
+	"fmt"
+	"time"
+)
+
+// taskParticipants returns the owner and collaborators of a task, leaving out the actor
+func taskParticipants(task *models.Task, actorID string) []string {
+	ids := make([]string, 0, len(task.Collaborators)+1)
+	if task.UserID != actorID {
+		ids = append(ids, task.UserID)
+	}
+	for _, collaborator := range task.Collaborators {
+		if collaborator.UserID != actorID {
+			ids = append(ids, collaborator.UserID)
+		}
+	}
+	return ids
+}
+
+// taskEmail is an email about an event that users can turn off
+type taskEmail struct {
+	event   string
+	subject string
+	body    string
+}
+
+// notifyUsers puts a notification in the inbox of each user and, when there is
+// an email for it, emails those who didn't turn the event off. It runs in the
+// background so the request doesn't wait on SMTP. Like the activity log,
+// failures are only logged because the change itself has already been applied.
+func notifyUsers(userIDs []string, notification models.Notification, email *taskEmail) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := notify.Record(ctx, userIDs, notification); err != nil {
+			logger.ErrorLogger.Printf("Failed to record %s notification: %v", notification.Type, err)
+		}
+		if email == nil {
+			return
+		}
+
+		users, err := userRepo.FindByIDs(ctx, toObjectIDs(userIDs))
+		if err != nil {
+			logger.ErrorLogger.Printf("Failed to load users for %s email: %v", email.event, err)
+			return
+		}
+		for i := range users {
+			if err := notify.Email(&users[i], email.event, email.subject, email.body); err != nil {
+				logger.ErrorLogger.Printf("Failed to send %s email to %s: %v", email.event, users[i].ID.Hex(), err)
+			}
+		}
+	}()
+}
+
+// notifyMentions tells mentioned users about the comment
+func notifyMentions(task *models.Task, comment *models.Comment, users []models.User) {
+	notification := models.TaskNotification(models.NotificationMention, task, comment.UserID,
+		fmt.Sprintf("You were mentioned in a comment on '%s'", task.Title))
+	notification.CommentID = comment.ID.Hex()
+
+	notifyUsers(mentionIDs(users), notification, &taskEmail{
+		event:   models.NotifyMentions,
+		subject: fmt.Sprintf("You were mentioned on '%s'", task.Title),
+		body: fmt.Sprintf(`
+			<p>Hi,</p>
+			<p>You were mentioned in a comment on <strong>%s</strong>:</p>
+			<blockquote>%s</blockquote>
+			<p>— Task Manager</p>
+		`, task.Title, comment.Content),
+	})
+}
+
+// notifyComment tells the task owner about a new comment, unless they wrote it
+// or were mentioned in it and already hear about it that way
+func notifyComment(task *models.Task, comment *models.Comment, mentioned []models.User) {
+	if task.UserID == comment.UserID {
+		return
+	}
+	for _, user := range mentioned {
+		if user.ID.Hex() == task.UserID {
+			return
+		}
+	}
+
+	notification := models.TaskNotification(models.NotificationComment, task, comment.UserID,
+		fmt.Sprintf("New comment on '%s'", task.Title))
+	notification.CommentID = comment.ID.Hex()
+
+	notifyUsers([]string{task.UserID}, notification, &taskEmail{
+		event:   models.NotifyComments,
+		subject: fmt.Sprintf("New comment on '%s'", task.Title),
+		body: fmt.Sprintf(`
+			<p>Hi,</p>
+			<p>There is a new comment on your task <strong>%s</strong>:</p>
+			<blockquote>%s</blockquote>
+			<p>— Task Manager</p>
+		`, task.Title, comment.Content),
+	})
+}
+
+// notifyCollaboratorAdded tells a user they were added to a task
+func notifyCollaboratorAdded(task *models.Task, collaboratorID, role, actorID string) {
+	notification := models.TaskNotification(models.NotificationCollaboratorAdded, task, actorID,
+		fmt.Sprintf("You were added as a %s on '%s'", role, task.Title))
+
+	notifyUsers([]string{collaboratorID}, notification, &taskEmail{
+		event:   models.NotifyCollaboratorAdded,
+		subject: fmt.Sprintf("You were added to '%s'", task.Title),
+		body: fmt.Sprintf(`
+			<p>Hi,</p>
+			<p>You were added as a %s on the task <strong>%s</strong>.</p>
+			<p>— Task Manager</p>
+		`, role, task.Title),
+	})
+}
+
+// notifyRoleChanged tells a collaborator their role on a task changed. It only
+// shows up in the inbox.
+func notifyRoleChanged(task *models.Task, collaboratorID, role, actorID string) {
+	notification := models.TaskNotification(models.NotificationRoleChanged, task, actorID,
+		fmt.Sprintf("You are now a %s on '%s'", role, task.Title))
+	notifyUsers([]string{collaboratorID}, notification, nil)
+}
+
+// notifyCollaboratorRemoved tells a user they no longer have access to a task.
+// It only shows up in the inbox.
+func notifyCollaboratorRemoved(task *models.Task, collaboratorID, actorID string) {
+	notification := models.TaskNotification(models.NotificationCollaboratorRemoved, task, actorID,
+		fmt.Sprintf("You were removed from '%s'", task.Title))
+	notifyUsers([]string{collaboratorID}, notification, nil)
+}
+
+// notifyStatusChange tells the owner and collaborators, other than whoever
+// made the change, that a task moved to another status
+func notifyStatusChange(task *models.Task, previous, actorID string) {
+	notification := models.TaskNotification(models.NotificationStatusChanged, task, actorID,
+		fmt.Sprintf("'%s' moved from %s to %s", task.Title, previous, task.Status))
+
+	notifyUsers(taskParticipants(task, actorID), notification, &taskEmail{
+		event:   models.NotifyStatusChanges,
+		subject: fmt.Sprintf("'%s' is now %s", task.Title, task.Status),
+		body: fmt.Sprintf(`
+			<p>Hi,</p>
+			<p>The task <strong>%s</strong> moved from <strong>%s</strong> to <strong>%s</strong>.</p>
+			<p>— Task Manager</p>
+		`, task.Title, previous, task.Status),
+	})
+}
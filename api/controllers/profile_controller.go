@@ -4,11 +4,19 @@ import (
 	"api/middleware"
 	"api/models"
 	"api/services"
+	"api/storage"
 	"api/utils"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -51,6 +59,27 @@ func (c *ProfileController) UpdateProfilePicture(w http.ResponseWriter, r *http.
 	utils.SendJSON(w, response)
 }
 
+// ServeProfilePicture streams a profile picture from file storage. Pictures are
+// public, like the static uploads directory they used to be served from.
+func (c *ProfileController) ServeProfilePicture(w http.ResponseWriter, r *http.Request) {
+	content, err := c.service.OpenProfilePicture(r.Context(), mux.Vars(r)["fileName"])
+	if err != nil {
+		if err == storage.ErrNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		utils.SendError(w, "Failed to load profile picture", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	if contentType := mime.TypeByExtension(filepath.Ext(mux.Vars(r)["fileName"])); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, content)
+}
+
 func (c *ProfileController) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
 	userClaims := r.Context().Value("user").(*middleware.UserClaims)
 	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
@@ -66,9 +95,98 @@ func (c *ProfileController) UpdatePreferences(w http.ResponseWriter, r *http.Req
 	}
 
 	if err := c.service.UpdatePreferences(r.Context(), userID, preferences); err != nil {
-		utils.SendError(w, err.Error(), http.StatusInternalServerError)
+		utils.SendAppError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	utils.SendJSON(w, map[string]string{"message": "Preferences updated successfully"})
-}
\ No newline at end of file
+}
+func (c *ProfileController) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := c.service.GetNotificationSettings(r.Context(), userID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch notification settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, settings)
+}
+
+func (c *ProfileController) UpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var settings models.NotificationSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.UpdateNotificationSettings(r.Context(), userID, settings); err != nil {
+		utils.SendError(w, "Failed to update notification settings", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, settings)
+}
+
+// ConfirmUnsubscribe answers a click on the link at the bottom of notification
+// emails. A GET must not change anything, since mail scanners follow links, so
+// it only shows a button that sends the POST Unsubscribe acts on.
+func (c *ProfileController) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.SendError(w, "Unsubscribe token is required", http.StatusBadRequest)
+		return
+	}
+
+	event, err := c.service.UnsubscribeEvent(token)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<body>
+	<p>Stop receiving emails about %s?</p>
+	<form method="POST" action="?token=%s">
+		<button type="submit">Unsubscribe</button>
+	</form>
+</body>
+</html>
+`, html.EscapeString(strings.ReplaceAll(event, "_", " ")), url.QueryEscape(token))
+}
+
+// Unsubscribe turns off the email named by an unsubscribe token. The signed
+// token identifies the user, so no login is needed. Mail clients POST here for
+// one-click unsubscribe (RFC 8058), as does the ConfirmUnsubscribe page.
+func (c *ProfileController) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.SendError(w, "Unsubscribe token is required", http.StatusBadRequest)
+		return
+	}
+
+	event, err := c.service.Unsubscribe(r.Context(), token)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{
+		"message": "You have been unsubscribed",
+		"event":   event,
+	})
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/models"
+	"api/services"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ProjectController struct {
+	service *services.ProjectService
+}
+
+func NewProjectController(service *services.ProjectService) *ProjectController {
+	return &ProjectController{service: service}
+}
+
+func (c *ProjectController) CreateProject(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var project models.Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	projectID, err := c.service.CreateProject(r.Context(), userClaims.ID, &project)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{"projectId": projectID.Hex()})
+}
+
+func (c *ProjectController) GetProjects(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	projects, err := c.service.ListProjects(r.Context(), userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch projects", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{"projects": projects})
+}
+
+func (c *ProjectController) GetProject(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	project, err := c.service.GetProject(r.Context(), projectID, userClaims.ID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, project)
+}
+
+func (c *ProjectController) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	var update models.Project
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	project, err := c.service.UpdateProject(r.Context(), projectID, userClaims.ID, &update)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, project)
+}
+
+// detachProjectTasks takes every task out of a project being deleted
+func detachProjectTasks(ctx context.Context, projectID string) error {
+	_, err := taskCollection.UpdateMany(ctx,
+		bson.M{"project_id": projectID},
+		bson.M{"$unset": bson.M{"project_id": ""}, "$inc": bson.M{"version": 1}},
+	)
+	return err
+}
+
+func (c *ProjectController) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.DeleteProject(r.Context(), projectID, userClaims.ID, detachProjectTasks); err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *ProjectController) AddMember(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	var request models.ProjectMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.AddMember(r.Context(), projectID, userClaims.ID, request.UserID); err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{"message": "Member added successfully"})
+}
+
+func (c *ProjectController) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	memberID := mux.Vars(r)["userId"]
+	if err := c.service.RemoveMember(r.Context(), projectID, userClaims.ID, memberID); err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{"message": "Member removed successfully"})
+}
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"api/configs"
+	"api/logger"
+	"api/middleware"
+	"api/models"
+	"api/repositories"
+	"api/utils"
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var revisionRepo = repositories.NewRevisionRepository(configs.GetCollection(configs.DB, "task_revisions"))
+
+// EnsureRevisionIndexes creates the unique index on revision numbers at startup
+func EnsureRevisionIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return revisionRepo.EnsureIndexes(ctx)
+}
+
+// maxRevisionAttempts bounds the retries when concurrent saves race for the
+// same revision number
+const maxRevisionAttempts = 5
+
+// saveRevision snapshots a task after it was saved. When the task has no
+// revisions yet, the previous state is stored first as a baseline so the very
+// first overwrite can still be rolled back. Revision numbers are unique per
+// task, so a save that loses a race for a number retries with the next one.
+// Like the activity log, failures are only logged because the change itself
+// has already been applied.
+func saveRevision(ctx context.Context, previous, task *models.Task, authorID, action string, restoredFrom int) {
+	taskID := task.ID.Hex()
+	for attempt := 1; ; attempt++ {
+		err := insertRevision(ctx, previous, task, authorID, action, restoredFrom)
+		if err == nil {
+			return
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt == maxRevisionAttempts {
+			logger.ErrorLogger.Printf("Failed to save revision of task %s: %v", taskID, err)
+			return
+		}
+	}
+}
+
+// insertRevision stores the revision after the task's latest one, preceded by
+// the baseline when there is none yet
+func insertRevision(ctx context.Context, previous, task *models.Task, authorID, action string, restoredFrom int) error {
+	taskID := task.ID.Hex()
+	latest, err := revisionRepo.LatestNumber(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	if latest == 0 && previous != nil {
+		latest++
+		baseline := &models.TaskRevision{
+			TaskID:    taskID,
+			Number:    latest,
+			Action:    models.RevisionBaseline,
+			AuthorID:  previous.UserID,
+			Snapshot:  *previous,
+			CreatedAt: previous.UpdatedAt,
+		}
+		if err = revisionRepo.Create(ctx, baseline); err != nil {
+			return err
+		}
+	}
+
+	return revisionRepo.Create(ctx, &models.TaskRevision{
+		TaskID:       taskID,
+		Number:       latest + 1,
+		Action:       action,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+		Snapshot:     *task,
+		CreatedAt:    time.Now(),
+	})
+}
+
+// findRevision loads the revision named by a route or query value, writing the error response itself
+func findRevision(ctx context.Context, w http.ResponseWriter, taskID, value string) (*models.TaskRevision, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		utils.SendError(w, "Invalid revision number", http.StatusBadRequest)
+		return nil, false
+	}
+
+	revision, err := revisionRepo.FindByNumber(ctx, taskID, number)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.SendError(w, "Revision not found", http.StatusNotFound)
+			return nil, false
+		}
+		utils.SendError(w, "Failed to fetch revision", http.StatusInternalServerError)
+		return nil, false
+	}
+	return revision, true
+}
+
+func GetTaskRevisions(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	revisions, err := revisionRepo.FindByTask(ctx, taskID.Hex())
+	if err != nil {
+		utils.SendError(w, "Failed to fetch revisions", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"revisions": revisions,
+		"total":     len(revisions),
+	})
+}
+
+func GetTaskRevision(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	revision, ok := findRevision(ctx, w, taskID.Hex(), mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+
+	utils.SendJSON(w, revision)
+}
+
+// DiffTaskRevisions compares two revisions given as ?from=&to=
+func DiffTaskRevisions(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, taskID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	from, ok := findRevision(ctx, w, taskID.Hex(), r.URL.Query().Get("from"))
+	if !ok {
+		return
+	}
+	to, ok := findRevision(ctx, w, taskID.Hex(), r.URL.Query().Get("to"))
+	if !ok {
+		return
+	}
+
+	changes := models.DiffTasks(&from.Snapshot, &to.Snapshot)
+	if changes == nil {
+		changes = make([]models.FieldChange, 0)
+	}
+
+	utils.SendJSON(w, models.RevisionDiff{
+		TaskID:  taskID.Hex(),
+		From:    from.Number,
+		To:      to.Number,
+		Changes: changes,
+	})
+}
+
+// RestoreTaskRevision rolls the task's content back to a revision: title,
+// description, due date, priority, tags and recurrence. Status, project and
+// collaborators are left alone since they have their own rules and endpoints.
+// The restored state is saved as a new revision.
+func RestoreTaskRevision(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	existing, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	if ok := checkIfMatch(w, r, existing); !ok {
+		return
+	}
+
+	revision, ok := findRevision(ctx, w, taskID.Hex(), mux.Vars(r)["number"])
+	if !ok {
+		return
+	}
+
+	snapshot := revision.Snapshot
+	// Keep the position in the series, as UpdateTask does
+	if snapshot.Recurrence != nil && existing.Recurrence != nil {
+		snapshot.Recurrence.Occurrence = existing.Recurrence.Occurrence
+	}
+	// The current reminders follow the restored due date
+	snapshot.Reminders = existing.Reminders
+	snapshot.ArmReminders(existing.Reminders, !snapshot.DueDate.Equal(existing.DueDate))
+
+	result, err := taskCollection.UpdateOne(ctx, versionFilter(taskID, existing.Version), bson.M{
+		"$set": bson.M{
+			"title":       snapshot.Title,
+			"description": snapshot.Description,
+			"due_date":    snapshot.DueDate,
+			"priority":    snapshot.Priority,
+			"tags":        snapshot.Tags,
+			"recurrence":  snapshot.Recurrence,
+			"reminders":   snapshot.Reminders,
+			"updated_at":  time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to restore revision", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		sendVersionConflict(w)
+		return
+	}
+
+	restored, err := getTaskByID(ctx, taskID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch restored task", http.StatusInternalServerError)
+		return
+	}
+
+	saveRevision(ctx, existing, restored, userClaims.ID, models.RevisionRestored, revision.Number)
+	recordActivity(ctx, restored, userClaims.ID, models.ActivityTaskRestored, models.DiffTasks(existing, restored))
+
+	setTaskETag(w, restored)
+	utils.SendJSON(w, restored)
+}
@@ -22,29 +22,65 @@ func GetTaskStatistics(w http.ResponseWriter, r *http.Request) {
 	userClaims := r.Context().Value("user").(*middleware.UserClaims)
 	userID := userClaims.ID
 
+	// Scope to a single project when asked, otherwise to the user's own tasks
+	projectID := r.URL.Query().Get("project_id")
+	// Trashed and archived tasks don't count
+	scope := bson.M{
+		"user_id":     userID,
+		"deleted_at":  bson.M{"$exists": false},
+		"archived_at": bson.M{"$exists": false},
+	}
+	if projectID != "" {
+		isMember, err := projectRepo.IsMember(ctx, projectID, userID)
+		if err != nil {
+			http.Error(w, `{"error": "Failed to verify project"}`, http.StatusInternalServerError)
+			return
+		}
+		if !isMember {
+			http.Error(w, `{"error": "Project not found or unauthorized"}`, http.StatusNotFound)
+			return
+		}
+		scope = bson.M{
+			"project_id":  projectID,
+			"deleted_at":  bson.M{"$exists": false},
+			"archived_at": bson.M{"$exists": false},
+		}
+	}
+	withScope := func(filter bson.M) bson.M {
+		for key, value := range scope {
+			filter[key] = value
+		}
+		return filter
+	}
+
 	// Get total tasks
-	totalTasks, err := taskCollection.CountDocuments(ctx, bson.M{"user_id": userID})
+	totalTasks, err := taskCollection.CountDocuments(ctx, withScope(bson.M{}))
 	if err != nil {
 		http.Error(w, `{"error": "Failed to fetch total tasks"}`, http.StatusInternalServerError)
 		return
 	}
 
+	// Which statuses count as done comes from the workflows in scope
+	doneClauses, err := doneStatusClauses(ctx, userID, projectID)
+	if err != nil {
+		http.Error(w, `{"error": "Failed to load workflow"}`, http.StatusInternalServerError)
+		return
+	}
+
 	// Get completed tasks
-	completedTasks, err := taskCollection.CountDocuments(ctx, bson.M{
-		"user_id": userID,
-		"status":  "Completed",
-	})
+	completedTasks, err := taskCollection.CountDocuments(ctx, withScope(bson.M{
+		"$or": doneClauses,
+	}))
 	if err != nil {
 		http.Error(w, `{"error": "Failed to fetch completed tasks"}`, http.StatusInternalServerError)
 		return
 	}
 
 	// Get overdue tasks
-	overdueTasks, err := taskCollection.CountDocuments(ctx, bson.M{
-		"user_id":  userID,
+	overdueTasks, err := taskCollection.CountDocuments(ctx, withScope(bson.M{
 		"due_date": bson.M{"$lt": time.Now()},
-		"status":   bson.M{"$ne": "Completed"},
-	})
+		"$nor":     doneClauses,
+	}))
 	if err != nil {
 		http.Error(w, `{"error": "Failed to fetch overdue tasks"}`, http.StatusInternalServerError)
 		return
@@ -56,42 +92,52 @@ func GetTaskStatistics(w http.ResponseWriter, r *http.Request) {
 		completionRate = float64(completedTasks) / float64(totalTasks) * 100
 	}
 
-	// Get task count by priority
-	priorityCounts := make(map[string]int)
-	cursor, err := taskCollection.Aggregate(ctx, mongo.Pipeline{
-		bson.D{{Key: "$match", Value: bson.M{"user_id": userID}}},
-		bson.D{{Key: "$group", Value: bson.M{
-			"_id":   "$priority",
-			"count": bson.M{"$sum": 1},
-		}}},
-	})
-	if err == nil {
-		var results []bson.M
-		if err = cursor.All(ctx, &results); err == nil {
-			for _, result := range results {
-				priority, _ := result["_id"].(string)
-				count, _ := result["count"].(int32)
-				priorityCounts[priority] = int(count)
-			}
-		}
-	}
+	// Get task count by priority and by project
+	statusCounts := countTasksBy(ctx, withScope(bson.M{}), "$status")
+	priorityCounts := countTasksBy(ctx, withScope(bson.M{}), "$priority")
+	projectCounts := countTasksBy(ctx, withScope(bson.M{
+		"project_id": bson.M{"$exists": true},
+	}), "$project_id")
 
 	// TODO: Add tag-based stats here if needed in future
 
 	stats := models.TaskStatistics{
 		ID:             primitive.NewObjectID(),
 		UserID:         userID,
+		ProjectID:      projectID,
 		TotalTasks:     int(totalTasks),
 		CompletedTasks: int(completedTasks),
 		PendingTasks:   int(totalTasks - completedTasks),
 		OverdueTasks:   int(overdueTasks),
 		CompletionRate: completionRate,
+		ByStatus:       statusCounts,
 		ByPriority:     priorityCounts,
+		ByProject:      projectCounts,
 		UpdatedAt:      time.Now(),
 	}
 
 	json.NewEncoder(w).Encode(stats)
 }
 
-
-
+// countTasksBy groups the matching tasks by a field and counts each group
+func countTasksBy(ctx context.Context, match bson.M, field string) map[string]int {
+	counts := make(map[string]int)
+	cursor, err := taskCollection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   field,
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err == nil {
+		var results []bson.M
+		if err = cursor.All(ctx, &results); err == nil {
+			for _, result := range results {
+				key, _ := result["_id"].(string)
+				count, _ := result["count"].(int32)
+				counts[key] = int(count)
+			}
+		}
+	}
+	return counts
+}
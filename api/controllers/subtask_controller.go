@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// countOpenSubtasks returns how many direct children of a task are not done.
+// Subtasks share their parent's owner and project, and so its workflow.
+func countOpenSubtasks(ctx context.Context, taskID primitive.ObjectID, workflow *models.Workflow) (int64, error) {
+	return taskCollection.CountDocuments(ctx, bson.M{
+		"parent_id":  taskID.Hex(),
+		"status":     bson.M{"$nin": workflow.DoneStatuses()},
+		"deleted_at": bson.M{"$exists": false},
+	})
+}
+
+// collectSubtaskIDs walks the hierarchy below a task and returns every descendant ID
+func collectSubtaskIDs(ctx context.Context, taskID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	queue := []string{taskID.Hex()}
+
+	for len(queue) > 0 {
+		cursor, err := taskCollection.Find(ctx,
+			bson.M{"parent_id": bson.M{"$in": queue}},
+			options.Find().SetProjection(bson.M{"_id": 1}),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var children []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &children); err != nil {
+			return nil, err
+		}
+
+		queue = queue[:0]
+		for _, child := range children {
+			ids = append(ids, child.ID)
+			queue = append(queue, child.ID.Hex())
+		}
+	}
+
+	return ids, nil
+}
+
+// rollUpParentStatus recomputes a parent's status from its subtasks and keeps
+// walking up the hierarchy while ancestors change.
+func rollUpParentStatus(ctx context.Context, parentID string) error {
+	for parentID != "" {
+		id, err := primitive.ObjectIDFromHex(parentID)
+		if err != nil {
+			return err
+		}
+
+		var parent models.Task
+		if err := taskCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&parent); err != nil {
+			return err
+		}
+
+		workflow, err := taskWorkflow(ctx, &parent)
+		if err != nil {
+			return err
+		}
+
+		total, err := taskCollection.CountDocuments(ctx, bson.M{
+			"parent_id":  parentID,
+			"deleted_at": bson.M{"$exists": false},
+		})
+		if err != nil {
+			return err
+		}
+		open, err := countOpenSubtasks(ctx, id, workflow)
+		if err != nil {
+			return err
+		}
+
+		// A parent without children keeps whatever status it was given
+		if total == 0 {
+			return nil
+		}
+
+		status := parent.Status
+		if open == 0 && !workflow.IsDone(parent.Status) {
+			status = workflow.CompletedStatus()
+		} else if open > 0 && workflow.IsDone(parent.Status) {
+			status = workflow.ReopenedStatus()
+		}
+		if status == parent.Status {
+			return nil
+		}
+
+		now := time.Now()
+		update := bson.M{
+			"$set": bson.M{"status": status, "updated_at": now},
+			"$inc": bson.M{"version": 1},
+		}
+		setCompletion(update, workflow.IsDone(status), parent.CompletedAt, now)
+		if _, err := taskCollection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+			return err
+		}
+
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func CreateSubtask(w http.ResponseWriter, r *http.Request) {
+	parentID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Verify parent exists and the user may edit it
+	parent, err := authorizeTask(ctx, parentID, userClaims.ID, models.ActionEdit)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	var task models.Task
+	if err = json.NewDecoder(r.Body).Decode(&task); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task.ProjectID = parent.ProjectID
+	task.CreatedAt = time.Now()
+
+	workflow, err := taskWorkflow(ctx, parent)
+	if err != nil {
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
+		return
+	}
+	prepareNewTask(&task, workflow)
+	task.ParentID = parentID.Hex()
+
+	// Subtasks belong to the owner of their parent
+	if err = validateAndPrepareTask(&task, parent.UserID, workflow); err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := taskCollection.InsertOne(ctx, task)
+	if err != nil {
+		utils.SendError(w, "Failed to create subtask", http.StatusInternalServerError)
+		return
+	}
+
+	task.ID = result.InsertedID.(primitive.ObjectID)
+	recordSubtaskCreated(ctx, parent, &task, userClaims.ID)
+	saveRevision(ctx, nil, &task, userClaims.ID, models.RevisionCreated, 0)
+
+	// A new open subtask reopens a parent that was already completed
+	if err = rollUpParentStatus(ctx, task.ParentID); err != nil {
+		utils.SendError(w, "Failed to update parent task", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{
+		"taskId": task.ID.Hex(),
+	})
+}
+
+func GetSubtasks(w http.ResponseWriter, r *http.Request) {
+	parentID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = authorizeTask(ctx, parentID, userClaims.ID, models.ActionView); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	cursor, err := taskCollection.Find(ctx,
+		bson.M{"parent_id": parentID.Hex(), "deleted_at": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.M{"created_at": 1}),
+	)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch subtasks", http.StatusInternalServerError)
+		return
+	}
+
+	subtasks := make([]models.Task, 0)
+	if err = cursor.All(ctx, &subtasks); err != nil {
+		utils.SendError(w, "Failed to decode subtasks", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"subtasks": subtasks,
+		"total":    len(subtasks),
+	})
+}
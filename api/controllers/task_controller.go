@@ -2,13 +2,19 @@ package controllers
 
 import (
 	"api/configs"
+	apperrors "api/errors"
+	"api/jsonpatch"
 	"api/middleware"
 	"api/models"
+	"api/repositories"
 	"api/utils"
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
+	"reflect"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,21 +23,81 @@ import (
 )
 
 var taskCollection = configs.GetCollection(configs.DB, "tasks")
+var projectRepo = repositories.NewProjectRepository(configs.GetCollection(configs.DB, "projects"))
+var userRepo = repositories.NewUserRepository(configs.GetCollection(configs.DB, "users"))
 
 // Common task operations
-func getTaskByID(ctx context.Context, taskID primitive.ObjectID, userID string) (*models.Task, error) {
+func getTaskByID(ctx context.Context, taskID primitive.ObjectID) (*models.Task, error) {
 	var task models.Task
-	err := taskCollection.FindOne(ctx, bson.M{
-		"_id":     taskID,
-		"user_id": userID,
-	}).Decode(&task)
+	err := taskCollection.FindOne(ctx, bson.M{"_id": taskID}).Decode(&task)
 	return &task, err
 }
 
-func validateAndPrepareTask(task *models.Task, userID string) error {
+// ensureProjectMember rejects assigning a task to a project the user is not part of
+func ensureProjectMember(ctx context.Context, w http.ResponseWriter, projectID string, userID string) bool {
+	if projectID == "" {
+		return true
+	}
+	isMember, err := projectRepo.IsMember(ctx, projectID, userID)
+	if err != nil {
+		utils.SendError(w, "Failed to verify project", http.StatusInternalServerError)
+		return false
+	}
+	if !isMember {
+		utils.SendError(w, "Project not found or unauthorized", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// checkSubtasksCompleted rejects completing a parent while any of its subtasks are still open
+func checkSubtasksCompleted(ctx context.Context, taskID primitive.ObjectID, workflow *models.Workflow) error {
+	open, err := countOpenSubtasks(ctx, taskID, workflow)
+	if err != nil {
+		return taskFailure("Failed to check subtasks", err)
+	}
+	if open > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("Task has %d open subtasks", open))
+	}
+	return nil
+}
+
+func validateAndPrepareTask(task *models.Task, userID string, workflow *models.Workflow) error {
 	task.UserID = userID
+	// Dependencies are managed through their own endpoints
+	task.BlockedBy = nil
+	task.Blocks = nil
 	task.UpdatedAt = time.Now()
-	return task.Validate()
+	return task.Validate(workflow)
+}
+
+// prepareNewTask fills in what a task being created gets from its workflow:
+// the starting status when none was given, and completed_at when it starts out done.
+// It also arms the task's reminders, giving it the default ones if none were asked for.
+// Fields the server manages are cleared, so callers set the parent afterwards.
+func prepareNewTask(task *models.Task, workflow *models.Workflow) {
+	task.ID = primitive.NilObjectID
+	task.ParentID = ""
+	task.Collaborators = nil
+	task.RecurrenceOf = ""
+	task.Rank = ""
+	task.ArchivedAt = nil
+	task.UnarchivedAt = nil
+	task.DeletedAt = nil
+	task.DeletedBy = ""
+	task.TrashedWith = ""
+	if task.Status == "" {
+		task.Status = workflow.InitialStatus()
+	}
+	if task.Reminders == nil {
+		task.Reminders = models.DefaultReminders()
+	}
+	task.ArmReminders(nil, true)
+	task.CompletedAt = nil
+	task.Version = 0
+	if workflow.IsDone(task.Status) {
+		task.CompletedAt = &task.CreatedAt
+	}
 }
 
 // HTTP Handlers
@@ -44,21 +110,72 @@ func CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A task created under a parent follows the same rules as CreateSubtask:
+	// the user must be able to edit the parent, and the subtask belongs to the
+	// parent's owner and project
+	ownerID := userClaims.ID
+	var parent *models.Task
+	if task.ParentID != "" {
+		parentID, err := primitive.ObjectIDFromHex(task.ParentID)
+		if err != nil {
+			utils.SendError(w, "Invalid parent task ID", http.StatusBadRequest)
+			return
+		}
+		if parent, err = authorizeTask(ctx, parentID, userClaims.ID, models.ActionEdit); err != nil {
+			sendTaskAccessError(w, err)
+			return
+		}
+		ownerID = parent.UserID
+		task.ProjectID = parent.ProjectID
+	} else if ok := ensureProjectMember(ctx, w, task.ProjectID, userClaims.ID); !ok {
+		return
+	}
+
+	var workflow *models.Workflow
+	var err error
+	if parent != nil {
+		workflow, err = taskWorkflow(ctx, parent)
+	} else {
+		workflow, err = workflowService.Resolve(ctx, userClaims.ID, task.ProjectID)
+	}
+	if err != nil {
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
+		return
+	}
+
 	task.CreatedAt = time.Now()
-	if err := validateAndPrepareTask(&task, userClaims.ID); err != nil {
+	prepareNewTask(&task, workflow)
+	if parent != nil {
+		task.ParentID = parent.ID.Hex()
+	}
+	if err := validateAndPrepareTask(&task, ownerID, workflow); err != nil {
 		utils.SendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
 	result, err := taskCollection.InsertOne(ctx, task)
 	if err != nil {
 		utils.SendError(w, "Failed to create task", http.StatusInternalServerError)
 		return
 	}
 
+	task.ID = result.InsertedID.(primitive.ObjectID)
+	if parent != nil {
+		recordSubtaskCreated(ctx, parent, &task, userClaims.ID)
+	} else {
+		recordActivity(ctx, &task, userClaims.ID, models.ActivityTaskCreated, nil)
+	}
+	saveRevision(ctx, nil, &task, userClaims.ID, models.RevisionCreated, 0)
+
+	// A new open subtask reopens a parent that was already completed
+	if err = rollUpParentStatus(ctx, task.ParentID); err != nil {
+		utils.SendError(w, "Failed to update parent task", http.StatusInternalServerError)
+		return
+	}
+
 	utils.SendJSON(w, map[string]string{
 		"taskId": result.InsertedID.(primitive.ObjectID).Hex(),
 	})
@@ -68,22 +185,18 @@ func GetUserTasks(w http.ResponseWriter, r *http.Request) {
 	userClaims := r.Context().Value("user").(*middleware.UserClaims)
 	params := utils.GetPaginationFromRequest(r)
 
-	baseFilter := bson.M{
-        "$or": []bson.M{
-            {"user_id": userClaims.ID},
-            {"collaborators": userClaims.ID},
-        },
-    }
-    
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
 	dateRange := &utils.DateRange{
 		StartDate: r.URL.Query().Get("start_date"),
 		EndDate:   r.URL.Query().Get("end_date"),
 	}
 
-	filter := utils.BuildSearchFilter(baseFilter, params, dateRange)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	filter, ok := taskListFilter(ctx, w, userClaims.ID, r.URL.Query().Get("project_id"), params, dateRange)
+	if !ok {
+		return
+	}
 
 	results, total, err := utils.ExecutePaginatedQuery(ctx, taskCollection, filter, params)
 	if err != nil {
@@ -116,6 +229,23 @@ func GetUserTasks(w http.ResponseWriter, r *http.Request) {
 	utils.SendJSON(w, response)
 }
 
+// taskListFilter matches the tasks a user can list: a project's when one is
+// given, otherwise every task the user owns or collaborates on
+func taskListFilter(ctx context.Context, w http.ResponseWriter, userID, projectID string, params utils.PaginationParams, dateRange *utils.DateRange) (bson.M, bool) {
+	baseFilter := visibleTasksFilter(userID)
+
+	// Project members see every task in the project
+	if projectID != "" {
+		if ok := ensureProjectMember(ctx, w, projectID, userID); !ok {
+			return nil, false
+		}
+		baseFilter = bson.M{"project_id": projectID}
+	}
+	baseFilter["deleted_at"] = bson.M{"$exists": false}
+
+	return utils.BuildSearchFilter(baseFilter, params, dateRange), true
+}
+
 func GetTask(w http.ResponseWriter, r *http.Request) {
 	taskID, err := utils.GetObjectIDFromRequest(r, "id")
 	if err != nil {
@@ -127,16 +257,17 @@ func GetTask(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	task, err := getTaskByID(ctx, taskID, userClaims.ID)
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionView)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			utils.SendError(w, "Task not found or unauthorized", http.StatusNotFound)
-			return
-		}
-		utils.SendError(w, "Failed to fetch task", http.StatusInternalServerError)
+		sendTaskAccessError(w, err)
 		return
 	}
 
+	setTaskETag(w, task)
+	if header := r.Header.Get("If-None-Match"); header != "" && etagMatches(header, task, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	utils.SendJSON(w, task)
 }
 
@@ -151,14 +282,13 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Verify task exists and belongs to user
-	_, err = getTaskByID(ctx, taskID, userClaims.ID)
+	// Verify task exists and the user may edit it
+	existing, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			utils.SendError(w, "Task not found or unauthorized", http.StatusNotFound)
-			return
-		}
-		utils.SendError(w, "Failed to verify task", http.StatusInternalServerError)
+		sendTaskAccessError(w, err)
+		return
+	}
+	if ok := checkIfMatch(w, r, existing); !ok {
 		return
 	}
 
@@ -168,48 +298,288 @@ func UpdateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = validateAndPrepareTask(&task, userClaims.ID); err != nil {
+	if task.ProjectID != existing.ProjectID {
+		if ok := ensureProjectMember(ctx, w, task.ProjectID, userClaims.ID); !ok {
+			return
+		}
+	}
+
+	// The task's project, possibly a new one, decides which statuses are valid
+	workflow, err := workflowService.Resolve(ctx, existing.UserID, task.ProjectID)
+	if err != nil {
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
+		return
+	}
+
+	// Editors update the task on the owner's behalf
+	if err = validateAndPrepareTask(&task, existing.UserID, workflow); err != nil {
 		utils.SendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":       task.Title,
-			"description": task.Description,
-			"due_date":    task.DueDate,
-			"priority":    task.Priority,
-			"status":      task.Status,
-			"tags" :       task.Tags, 
-			"updated_at":  task.UpdatedAt,
-			
-		},
+	updatedTask, err := saveTaskChanges(ctx, existing, &task, models.EditableTaskFields, workflow, userClaims.ID)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
 	}
+	setTaskETag(w, updatedTask)
+	utils.SendJSON(w, updatedTask)
+}
+
+// PatchTask changes only the fields named in the body. It accepts an RFC 7396
+// merge patch (application/merge-patch+json or plain JSON) or an RFC 6902 JSON
+// Patch (application/json-patch+json) against the task's editable fields, and
+// validates just the fields that actually change.
+func PatchTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	result, err := taskCollection.UpdateOne(ctx, bson.M{
-		"_id":     taskID,
-		"user_id": userClaims.ID,
-	}, update)
+	existing, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	if ok := checkIfMatch(w, r, existing); !ok {
+		return
+	}
 
+	original, err := editableTaskDocument(existing)
 	if err != nil {
-		utils.SendError(w, "Failed to update task", http.StatusInternalServerError)
+		utils.SendError(w, "Failed to prepare task", http.StatusInternalServerError)
+		return
+	}
+	// Patches work on a copy so original stays the baseline for what changed
+	target, err := editableTaskDocument(existing)
+	if err != nil {
+		utils.SendError(w, "Failed to prepare task", http.StatusInternalServerError)
+		return
+	}
+
+	var patched any
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case jsonpatch.JSONPatchContentType:
+		var operations []jsonpatch.PatchOperation
+		if err = json.NewDecoder(r.Body).Decode(&operations); err != nil {
+			utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if patched, err = jsonpatch.ApplyJSONPatch(target, operations); err != nil {
+			utils.SendError(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	case jsonpatch.MergePatchContentType, "application/json", "":
+		var patch any
+		if err = json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		patched = jsonpatch.ApplyMergePatch(target, patch)
+	default:
+		w.Header().Set("Accept-Patch", jsonpatch.MergePatchContentType+", "+jsonpatch.JSONPatchContentType)
+		utils.SendError(w, "Unsupported patch format", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	document, ok := patched.(map[string]any)
+	if !ok {
+		utils.SendError(w, "Patch must leave the task an object", http.StatusUnprocessableEntity)
 		return
 	}
+	for field := range document {
+		if !slices.Contains(models.EditableTaskFields, field) {
+			utils.SendError(w, fmt.Sprintf("Field %q cannot be changed", field), http.StatusUnprocessableEntity)
+			return
+		}
+	}
 
-	if result.ModifiedCount == 0 {
-		utils.SendError(w, "Task not found or unauthorized", http.StatusNotFound)
+	var changed []string
+	for _, field := range models.EditableTaskFields {
+		if !reflect.DeepEqual(original[field], document[field]) {
+			changed = append(changed, field)
+		}
+	}
+	if len(changed) == 0 {
+		setTaskETag(w, existing)
+		utils.SendJSON(w, existing)
 		return
 	}
 
-	updatedTask, err := getTaskByID(ctx, taskID, userClaims.ID)
+	// Rebuild the task from the patched fields; everything else stays as stored
+	var task models.Task
+	encoded, _ := json.Marshal(document)
+	if err = json.Unmarshal(encoded, &task); err != nil {
+		utils.SendError(w, "Invalid field value: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	task.ID = existing.ID
+	task.UserID = existing.UserID
+	task.UpdatedAt = time.Now()
+
+	if slices.Contains(changed, "project_id") {
+		if ok := ensureProjectMember(ctx, w, task.ProjectID, userClaims.ID); !ok {
+			return
+		}
+	}
+
+	workflow, err := workflowService.Resolve(ctx, existing.UserID, task.ProjectID)
 	if err != nil {
-		utils.SendError(w, "Failed to fetch updated task", http.StatusInternalServerError)
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
 		return
 	}
 
+	// A task moving to another project must fit that project's workflow
+	validate := changed
+	if slices.Contains(changed, "project_id") && !slices.Contains(changed, "status") {
+		validate = append(validate, "status")
+	}
+	if err = task.ValidateFields(workflow, validate...); err != nil {
+		utils.SendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updatedTask, err := saveTaskChanges(ctx, existing, &task, changed, workflow, userClaims.ID)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	setTaskETag(w, updatedTask)
 	utils.SendJSON(w, updatedTask)
 }
 
+// editableTaskDocument returns the task's editable fields as a JSON object for
+// patches to apply to
+func editableTaskDocument(task *models.Task) (map[string]any, error) {
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	if err = json.Unmarshal(encoded, &document); err != nil {
+		return nil, err
+	}
+	for field := range document {
+		if !slices.Contains(models.EditableTaskFields, field) {
+			delete(document, field)
+		}
+	}
+	return document, nil
+}
+
+// saveTaskChanges writes the given fields of task over existing, enforcing the
+// workflow on a status change and doing the follow-up work an edit triggers.
+// It returns the updated task, or an error for sendTaskAccessError to report.
+func saveTaskChanges(ctx context.Context, existing, task *models.Task, fields []string, workflow *models.Workflow, actorID string) (*models.Task, error) {
+	taskID := existing.ID
+	completing := workflow.IsDone(task.Status) && !workflow.IsDone(existing.Status)
+	if task.Status != existing.Status {
+		if err := checkTransition(workflow, existing.Status, task.Status); err != nil {
+			return nil, err
+		}
+		if err := checkNotBlocked(ctx, existing, task.Status, workflow); err != nil {
+			return nil, err
+		}
+	}
+	if completing {
+		if err := checkSubtasksCompleted(ctx, taskID, workflow); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the position in the series when the rule itself is edited
+	if task.Recurrence != nil && existing.Recurrence != nil {
+		task.Recurrence.Occurrence = existing.Recurrence.Occurrence
+	}
+
+	// Moving the due date re-arms every reminder
+	dueDateChanged := slices.Contains(fields, "due_date") && !task.DueDate.Equal(existing.DueDate)
+	if dueDateChanged || slices.Contains(fields, "reminders") {
+		if task.Reminders == nil {
+			task.Reminders = existing.Reminders
+		}
+		task.ArmReminders(existing.Reminders, dueDateChanged)
+		if !slices.Contains(fields, "reminders") {
+			fields = append(fields[:len(fields):len(fields)], "reminders")
+		}
+	}
+
+	values := bson.M{
+		"title":       task.Title,
+		"description": task.Description,
+		"due_date":    task.DueDate,
+		"priority":    task.Priority,
+		"status":      task.Status,
+		"tags":        task.Tags,
+		"recurrence":  task.Recurrence,
+		"project_id":  task.ProjectID,
+		"reminders":   task.Reminders,
+	}
+	set := bson.M{"updated_at": task.UpdatedAt}
+	unset := bson.M{}
+	for _, field := range fields {
+		// Leaving a project removes the field so project filters keep working
+		if field == "project_id" && task.ProjectID == "" {
+			unset[field] = ""
+			continue
+		}
+		set[field] = values[field]
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	setCompletion(update, workflow.IsDone(task.Status), existing.CompletedAt, task.UpdatedAt)
+
+	result, err := taskCollection.UpdateOne(ctx, versionFilter(taskID, existing.Version), update)
+
+	if err != nil {
+		return nil, taskFailure("Failed to update task", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, errVersionConflict
+	}
+
+	if task.Status != existing.Status {
+		if err = rollUpParentStatus(ctx, existing.ParentID); err != nil {
+			return nil, taskFailure("Failed to update parent task", err)
+		}
+	}
+
+	if completing && task.Recurrence != nil {
+		task.ID = taskID
+		task.ParentID = existing.ParentID
+		task.Collaborators = existing.Collaborators
+		if err = scheduleNextOccurrence(ctx, task, workflow); err != nil {
+			return nil, taskFailure("Failed to schedule next occurrence", err)
+		}
+	}
+
+	updatedTask, err := getTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, taskFailure("Failed to fetch updated task", err)
+	}
+
+	if changes := models.DiffTasks(existing, updatedTask); len(changes) > 0 {
+		recordActivity(ctx, updatedTask, actorID, models.ActivityTaskUpdated, changes)
+	}
+	if updatedTask.Status != existing.Status {
+		notifyStatusChange(updatedTask, existing.Status, actorID)
+	}
+	saveRevision(ctx, existing, updatedTask, actorID, models.RevisionUpdated, 0)
+	return updatedTask, nil
+}
+
+// DeleteTask moves a task to the trash. Its subtasks go with it unless the
+// caller asks to keep them, in which case they are promoted to top-level tasks.
+// Trashed tasks can be restored until they are purged.
 func DeleteTask(w http.ResponseWriter, r *http.Request) {
 	taskID, err := utils.GetObjectIDFromRequest(r, "id")
 	if err != nil {
@@ -221,24 +591,163 @@ func DeleteTask(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	result, err := taskCollection.DeleteOne(ctx, bson.M{
-		"_id":     taskID,
-		"user_id": userClaims.ID,
-	})
-
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionManage)
 	if err != nil {
-		utils.SendError(w, "Failed to delete task", http.StatusInternalServerError)
+		sendTaskAccessError(w, err)
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		utils.SendError(w, "Task not found or unauthorized", http.StatusNotFound)
+	mode := r.URL.Query().Get("subtasks")
+	if mode != "" && mode != "delete" && mode != "detach" {
+		utils.SendError(w, "subtasks must be delete or detach", http.StatusBadRequest)
+		return
+	}
+	if ok := checkIfMatch(w, r, task); !ok {
+		return
+	}
+	if err = trashTask(ctx, task, mode, userClaims.ID); err != nil {
+		sendTaskAccessError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
-func CalculateTaskStats(tasks []models.Task) map[string]int64 {
+
+// trashTask moves a task to the trash as DeleteTask describes; mode is how its
+// subtasks are handled. Errors are for sendTaskAccessError to report.
+func trashTask(ctx context.Context, task *models.Task, mode string, actorID string) error {
+	// Only the owner may trash a task; checked before the write so that a
+	// failed versioned update always means the task changed underneath us
+	if task.UserID != actorID {
+		return errTaskForbidden
+	}
+
+	now := time.Now()
+	filter := versionFilter(task.ID, task.Version)
+	filter["deleted_at"] = bson.M{"$exists": false}
+	result, err := taskCollection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"deleted_at": now, "deleted_by": actorID},
+		"$inc": bson.M{"version": 1},
+	})
+
+	if err != nil {
+		return taskFailure("Failed to delete task", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errVersionConflict
+	}
+
+	if mode == "detach" {
+		if _, err = taskCollection.UpdateMany(ctx,
+			bson.M{"parent_id": task.ID.Hex()},
+			bson.M{
+				"$unset": bson.M{"parent_id": ""},
+				"$set":   bson.M{"updated_at": now},
+				"$inc":   bson.M{"version": 1},
+			},
+		); err != nil {
+			return taskFailure("Failed to detach subtasks", err)
+		}
+	} else {
+		subtaskIDs, err := collectSubtaskIDs(ctx, task.ID)
+		if err != nil {
+			return taskFailure("Failed to fetch subtasks", err)
+		}
+		if len(subtaskIDs) > 0 {
+			// Subtasks that were already in the trash keep their own entry
+			if _, err = taskCollection.UpdateMany(ctx,
+				bson.M{"_id": bson.M{"$in": subtaskIDs}, "deleted_at": bson.M{"$exists": false}},
+				bson.M{
+					"$set": bson.M{
+						"deleted_at":   now,
+						"deleted_by":   actorID,
+						"trashed_with": task.ID.Hex(),
+					},
+					"$inc": bson.M{"version": 1},
+				},
+			); err != nil {
+				return taskFailure("Failed to delete subtasks", err)
+			}
+		}
+	}
+
+	// Removing an open subtask may complete its parent
+	if err = rollUpParentStatus(ctx, task.ParentID); err != nil {
+		return taskFailure("Failed to update parent task", err)
+	}
+
+	recordActivity(ctx, task, actorID, models.ActivityTaskDeleted, nil)
+
+	return nil
+}
+
+// setCompletion records or clears completed_at to match whether the new status
+// is a done one. A task moving between done statuses keeps its completion time.
+func setCompletion(update bson.M, done bool, completedAt *time.Time, now time.Time) {
+	if done {
+		if completedAt == nil {
+			update["$set"].(bson.M)["completed_at"] = now
+		}
+		return
+	}
+	if unset, ok := update["$unset"].(bson.M); ok {
+		unset["completed_at"] = ""
+		return
+	}
+	update["$unset"] = bson.M{"completed_at": ""}
+}
+
+// scheduleNextOccurrence creates the follow-up task for a completed recurring task.
+// It is a no-op when the series has ended or the next occurrence already exists.
+func scheduleNextOccurrence(ctx context.Context, task *models.Task, workflow *models.Workflow) error {
+	// Only the series owner's tasks count; recurrence_of is never taken from clients
+	count, err := taskCollection.CountDocuments(ctx, bson.M{
+		"recurrence_of": task.ID.Hex(),
+		"user_id":       task.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dueDate, ok := task.Recurrence.NextDueDate(task.DueDate, now)
+	if !ok {
+		return nil
+	}
+
+	rule := *task.Recurrence
+	rule.Occurrence++
+
+	next := models.Task{
+		Title:         task.Title,
+		Description:   task.Description,
+		DueDate:       dueDate,
+		Priority:      task.Priority,
+		Status:        workflow.InitialStatus(),
+		UserID:        task.UserID,
+		ParentID:      task.ParentID,
+		ProjectID:     task.ProjectID,
+		Collaborators: task.Collaborators,
+		Tags:          task.Tags,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Reminders:     task.Reminders,
+		Recurrence:    &rule,
+		RecurrenceOf:  task.ID.Hex(),
+	}
+	next.ArmReminders(nil, true)
+	if _, err = taskCollection.InsertOne(ctx, next); err != nil {
+		return err
+	}
+
+	return rollUpParentStatus(ctx, next.ParentID)
+}
+
+func CalculateTaskStats(tasks []models.Task, workflow *models.Workflow) map[string]int64 {
 	stats := map[string]int64{
 		"total":     int64(len(tasks)),
 		"completed": 0,
@@ -248,14 +757,13 @@ func CalculateTaskStats(tasks []models.Task) map[string]int64 {
 
 	now := time.Now()
 	for _, task := range tasks {
-		switch task.Status {
-		case "Completed":
+		if workflow.IsDone(task.Status) {
 			stats["completed"]++
-		case "Pending":
-			stats["pending"]++
-			if task.DueDate.Before(now) {
-				stats["overdue"]++
-			}
+			continue
+		}
+		stats["pending"]++
+		if task.DueDate.Before(now) {
+			stats["overdue"]++
 		}
 	}
 
@@ -273,14 +781,13 @@ func UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Verify task exists and belongs to user
-	_, err = getTaskByID(ctx, taskID, userClaims.ID)
+	// Verify task exists and the user may edit it
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionEdit)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			utils.SendError(w, "Task not found or unauthorized", http.StatusNotFound)
-			return
-		}
-		utils.SendError(w, "Failed to verify task", http.StatusInternalServerError)
+		sendTaskAccessError(w, err)
+		return
+	}
+	if ok := checkIfMatch(w, r, task); !ok {
 		return
 	}
 
@@ -293,136 +800,253 @@ func UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	updatedTask, err := changeTaskStatus(ctx, task, body.Status, nil, userClaims.ID)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	setTaskETag(w, updatedTask)
+	utils.SendJSON(w, updatedTask)
+}
+
+// changeTaskStatus moves a task to a status under its workflow's rules, along
+// with any extra fields to set in the same update. It returns the updated
+// task, or an error for sendTaskAccessError to report.
+func changeTaskStatus(ctx context.Context, task *models.Task, status string, extra bson.M, actorID string) (*models.Task, error) {
+	workflow, err := taskWorkflow(ctx, task)
+	if err != nil {
+		return nil, taskFailure("Failed to load workflow", err)
+	}
+	if err = checkTransition(workflow, task.Status, status); err != nil {
+		return nil, err
+	}
+
+	completing := workflow.IsDone(status) && !workflow.IsDone(task.Status)
+	if completing {
+		if err = checkSubtasksCompleted(ctx, task.ID, workflow); err != nil {
+			return nil, err
+		}
+	}
+	if status != task.Status {
+		if err = checkNotBlocked(ctx, task, status, workflow); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update status
-	update := bson.M{
-		"$set": bson.M{
-			"status":     body.Status,
-			"updated_at": time.Now(),
-		},
+	now := time.Now()
+	fields := bson.M{
+		"status":     status,
+		"updated_at": now,
 	}
-	result, err := taskCollection.UpdateOne(ctx, bson.M{
-		"_id":     taskID,
-		"user_id": userClaims.ID,
-	}, update)
+	for key, value := range extra {
+		fields[key] = value
+	}
+	update := bson.M{"$set": fields, "$inc": bson.M{"version": 1}}
+	setCompletion(update, workflow.IsDone(status), task.CompletedAt, now)
+	result, err := taskCollection.UpdateOne(ctx, versionFilter(task.ID, task.Version), update)
 
 	if err != nil {
-		utils.SendError(w, "Failed to update status", http.StatusInternalServerError)
-		return
+		return nil, taskFailure("Failed to update status", err)
 	}
-	if result.ModifiedCount == 0 {
-		utils.SendError(w, "No update made", http.StatusNotModified)
-		return
+	if result.MatchedCount == 0 {
+		return nil, errVersionConflict
+	}
+
+	if err = rollUpParentStatus(ctx, task.ParentID); err != nil {
+		return nil, taskFailure("Failed to update parent task", err)
+	}
+
+	// Completing a recurring task generates its next occurrence
+	if completing && task.Recurrence != nil {
+		if err = scheduleNextOccurrence(ctx, task, workflow); err != nil {
+			return nil, taskFailure("Failed to schedule next occurrence", err)
+		}
 	}
 
 	// Return updated task
-	updatedTask, err := getTaskByID(ctx, taskID, userClaims.ID)
+	updatedTask, err := getTaskByID(ctx, task.ID)
 	if err != nil {
-		utils.SendError(w, "Failed to fetch updated task", http.StatusInternalServerError)
-		return
+		return nil, taskFailure("Failed to fetch updated task", err)
 	}
-	utils.SendJSON(w, updatedTask)
-}
 
+	if status != task.Status {
+		recordActivity(ctx, updatedTask, actorID, models.ActivityStatusChanged, []models.FieldChange{
+			{Field: "status", Before: task.Status, After: updatedTask.Status},
+		})
+		notifyStatusChange(updatedTask, task.Status, actorID)
+	}
+	saveRevision(ctx, task, updatedTask, actorID, models.RevisionUpdated, 0)
+	return updatedTask, nil
+}
 
 // Collaboration endpoints
+type collaboratorRequest struct {
+	TaskID         string `json:"task_id"`
+	CollaboratorID string `json:"collaborator_id"`
+	Role           string `json:"role"`
+}
+
+// withoutCollaborator returns the collaborator list minus the given user
+func withoutCollaborator(collaborators []models.Collaborator, userID string) []models.Collaborator {
+	remaining := make([]models.Collaborator, 0, len(collaborators))
+	for _, collaborator := range collaborators {
+		if collaborator.UserID != userID {
+			remaining = append(remaining, collaborator)
+		}
+	}
+	return remaining
+}
+
+// setCollaborator gives a user a role on the task, replacing any earlier entry,
+// then records the change and tells them about it. The collaborator list is
+// written back whole, so the write only applies to the version task was read
+// at; errVersionConflict means someone changed the task in between.
+func setCollaborator(ctx context.Context, task *models.Task, collaboratorID, role, actorID string) error {
+	var previousRole any
+	if current := task.RoleFor(collaboratorID); current != "" {
+		previousRole = current
+	}
+
+	now := time.Now()
+	collaborators := append(withoutCollaborator(task.Collaborators, collaboratorID), models.Collaborator{
+		UserID:  collaboratorID,
+		Role:    role,
+		AddedAt: now,
+	})
+
+	result, err := taskCollection.UpdateOne(ctx, versionFilter(task.ID, task.Version), bson.M{
+		"$set": bson.M{"collaborators": collaborators, "updated_at": now},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return taskFailure("Failed to add collaborator", err)
+	}
+	if result.MatchedCount == 0 {
+		return errVersionConflict
+	}
+
+	recordActivity(ctx, task, actorID, models.ActivityCollaboratorAdded, []models.FieldChange{
+		{Field: "collaborators." + collaboratorID, Before: previousRole, After: role},
+	})
+	if previousRole == nil {
+		notifyCollaboratorAdded(task, collaboratorID, role, actorID)
+	} else if previousRole != role {
+		notifyRoleChanged(task, collaboratorID, role, actorID)
+	}
+	return nil
+}
+
+// AddCollaborator adds a collaborator with a role, or changes the role of an existing one
 func AddCollaborator(w http.ResponseWriter, r *http.Request) {
-    userClaims := r.Context().Value("user").(*middleware.UserClaims)
-    
-    var request struct {
-        TaskID        string `json:"task_id"`
-        CollaboratorID string `json:"collaborator_id"`
-    }
-    
-    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-        utils.SendError(w, "Invalid request body", http.StatusBadRequest)
-        return
-    }
-    
-    taskID, err := primitive.ObjectIDFromHex(request.TaskID)
-    if err != nil {
-        utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
-        return
-    }
-    
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-    
-    // Check if user owns the task
-    task, err := getTaskByID(ctx, taskID, userClaims.ID)
-    if err != nil {
-        utils.SendError(w, "Task not found", http.StatusNotFound)
-        return
-    }
-
-	fmt.Println(task)
-    
-    // Update collaborators
-    update := bson.M{
-        "$addToSet": bson.M{"collaborators": request.CollaboratorID},
-        "$set": bson.M{"updated_at": time.Now()},
-    }
-    
-    _, err = taskCollection.UpdateOne(
-        ctx,
-        bson.M{"_id": taskID},
-        update,
-    )
-    
-    if err != nil {
-        utils.SendError(w, "Failed to add collaborator", http.StatusInternalServerError)
-        return
-    }
-    
-    utils.SendJSON(w, map[string]string{"message": "Collaborator added successfully"})
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var request collaboratorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	taskID, err := primitive.ObjectIDFromHex(request.TaskID)
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+	collaboratorID, err := primitive.ObjectIDFromHex(request.CollaboratorID)
+	if err != nil {
+		utils.SendError(w, "Invalid collaborator ID", http.StatusBadRequest)
+		return
+	}
+	if request.Role == "" {
+		request.Role = models.RoleViewer
+	}
+	if !models.IsValidCollaboratorRole(request.Role) {
+		utils.SendError(w, "role must be viewer, commenter, or editor", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Only the owner manages collaborators
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, models.ActionManage)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+	if request.CollaboratorID == task.UserID {
+		utils.SendError(w, "The task owner cannot be a collaborator", http.StatusBadRequest)
+		return
+	}
+	if _, err = userRepo.FindByID(ctx, collaboratorID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.SendError(w, "User not found", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to verify user", http.StatusInternalServerError)
+		return
+	}
+
+	if err = setCollaborator(ctx, task, request.CollaboratorID, request.Role, userClaims.ID); err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	utils.SendJSON(w, map[string]string{"message": "Collaborator added successfully"})
 }
 
 func RemoveCollaborator(w http.ResponseWriter, r *http.Request) {
-    userClaims := r.Context().Value("user").(*middleware.UserClaims)
-    
-    var request struct {
-        TaskID        string `json:"task_id"`
-        CollaboratorID string `json:"collaborator_id"`
-    }
-    
-    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-        utils.SendError(w, "Invalid request body", http.StatusBadRequest)
-        return
-    }
-    
-    taskID, err := primitive.ObjectIDFromHex(request.TaskID)
-    if err != nil {
-        utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
-        return
-    }
-    
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-    
-    // Check if user owns the task
-    task, err := getTaskByID(ctx, taskID, userClaims.ID)
-    if err != nil {
-        utils.SendError(w, "Task not found", http.StatusNotFound)
-        return
-    }
-	println(task)
-    
-    // Remove collaborator
-    update := bson.M{
-        "$pull": bson.M{"collaborators": request.CollaboratorID},
-        "$set": bson.M{"updated_at": time.Now()},
-    }
-    
-    _, err = taskCollection.UpdateOne(
-        ctx,
-        bson.M{"_id": taskID},
-        update,
-    )
-    
-    if err != nil {
-        utils.SendError(w, "Failed to remove collaborator", http.StatusInternalServerError)
-        return
-    }
-    
-    utils.SendJSON(w, map[string]string{"message": "Collaborator removed successfully"})
-}
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
 
+	var request collaboratorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	taskID, err := primitive.ObjectIDFromHex(request.TaskID)
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Collaborators may remove themselves; anyone else needs the owner
+	action := models.ActionManage
+	if request.CollaboratorID == userClaims.ID {
+		action = models.ActionView
+	}
+	task, err := authorizeTask(ctx, taskID, userClaims.ID, action)
+	if err != nil {
+		sendTaskAccessError(w, err)
+		return
+	}
+
+	// The list is written back whole, so only over the version it was read from
+	result, err := taskCollection.UpdateOne(ctx, versionFilter(taskID, task.Version), bson.M{
+		"$set": bson.M{
+			"collaborators": withoutCollaborator(task.Collaborators, request.CollaboratorID),
+			"updated_at":    time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		utils.SendError(w, "Failed to remove collaborator", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		sendVersionConflict(w)
+		return
+	}
+
+	if role := task.RoleFor(request.CollaboratorID); role != "" && role != models.RoleOwner {
+		recordActivity(ctx, task, userClaims.ID, models.ActivityCollaboratorRemoved, []models.FieldChange{
+			{Field: "collaborators." + request.CollaboratorID, Before: role, After: nil},
+		})
+		notifyCollaboratorRemoved(task, request.CollaboratorID, userClaims.ID)
+	}
+
+	utils.SendJSON(w, map[string]string{"message": "Collaborator removed successfully"})
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"api/configs"
+	"api/logger"
+	"api/middleware"
+	"api/models"
+	"api/repositories"
+	"api/services"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var templateService = services.NewTemplateService(
+	repositories.NewTemplateRepository(configs.GetCollection(configs.DB, "task_templates")),
+)
+
+func CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var template models.TaskTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	created, err := templateService.CreateTemplate(ctx, userClaims.ID, &template)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, created)
+}
+
+func GetTemplates(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	templates, err := templateService.ListTemplates(ctx, userClaims.ID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch templates", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{"templates": templates})
+}
+
+func GetTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	template, err := templateService.GetTemplate(ctx, templateID, userClaims.ID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, template)
+}
+
+func UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var update models.TaskTemplate
+	if err = json.NewDecoder(r.Body).Decode(&update); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	template, err := templateService.UpdateTemplate(ctx, templateID, userClaims.ID, &update)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, template)
+}
+
+func DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = templateService.DeleteTemplate(ctx, templateID, userClaims.ID); err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// templateTask turns one task of a template into a task with its placeholders
+// filled in. It still has to go through validateAndPrepareTask.
+func templateTask(spec models.TemplateTask, checklist []string, variables map[string]string, start time.Time) (models.Task, error) {
+	var task models.Task
+	var err error
+	if task.Title, err = models.ExpandPlaceholders(spec.Title, variables); err != nil {
+		return task, err
+	}
+	description := models.ChecklistDescription(spec.Description, checklist)
+	if task.Description, err = models.ExpandPlaceholders(description, variables); err != nil {
+		return task, err
+	}
+	task.Tags = make([]string, 0, len(spec.Tags))
+	for _, tag := range spec.Tags {
+		expanded, err := models.ExpandPlaceholders(tag, variables)
+		if err != nil {
+			return task, err
+		}
+		task.Tags = append(task.Tags, expanded)
+	}
+	task.Priority = spec.Priority
+	task.DueDate = spec.DueDate(start)
+	return task, nil
+}
+
+// InstantiateTemplate creates a task, and the subtasks under it, from one of
+// the user's templates. Every task is checked before any is created, and the
+// ones already inserted are removed again if a later insert fails, so a
+// template either instantiates completely or not at all.
+func InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var request models.InstantiateTemplateRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	template, err := templateService.GetTemplate(ctx, templateID, userClaims.ID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if ok := ensureProjectMember(ctx, w, request.ProjectID, userClaims.ID); !ok {
+		return
+	}
+	workflow, err := workflowService.Resolve(ctx, userClaims.ID, request.ProjectID)
+	if err != nil {
+		utils.SendError(w, "Failed to load workflow", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	start := now
+	if request.Start != nil {
+		start = *request.Start
+	}
+	variables := map[string]string{"date": start.Format("2006-01-02")}
+	for name, value := range request.Variables {
+		variables[name] = value
+	}
+
+	// Build and validate every task up front
+	specs := append([]models.TemplateTask{template.TemplateTask}, template.Subtasks...)
+	tasks := make([]models.Task, 0, len(specs))
+	for i, spec := range specs {
+		var checklist []string
+		if i == 0 {
+			checklist = template.Checklist
+		}
+		task, err := templateTask(spec, checklist, variables, start)
+		if err != nil {
+			utils.SendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		task.ProjectID = request.ProjectID
+		task.CreatedAt = now
+		prepareNewTask(&task, workflow)
+		if err = validateAndPrepareTask(&task, userClaims.ID, workflow); err != nil {
+			utils.SendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tasks = append(tasks, task)
+	}
+
+	inserted := make([]primitive.ObjectID, 0, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		if i > 0 {
+			task.ParentID = tasks[0].ID.Hex()
+		}
+		result, err := taskCollection.InsertOne(ctx, task)
+		if err != nil {
+			removeInstantiatedTasks(inserted)
+			utils.SendError(w, "Failed to create task", http.StatusInternalServerError)
+			return
+		}
+		task.ID = result.InsertedID.(primitive.ObjectID)
+		inserted = append(inserted, task.ID)
+	}
+
+	subtaskIDs := []string{}
+	for i := range tasks {
+		task := &tasks[i]
+		if i > 0 {
+			subtaskIDs = append(subtaskIDs, task.ID.Hex())
+			recordSubtaskCreated(ctx, &tasks[0], task, userClaims.ID)
+		} else {
+			recordActivity(ctx, task, userClaims.ID, models.ActivityTaskCreated, nil)
+		}
+		saveRevision(ctx, nil, task, userClaims.ID, models.RevisionCreated, 0)
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"taskId":     tasks[0].ID.Hex(),
+		"subtaskIds": subtaskIDs,
+	})
+}
+
+// removeInstantiatedTasks deletes the tasks created by a failed instantiation.
+// It runs on its own context so that a timed-out request still cleans up.
+func removeInstantiatedTasks(taskIDs []primitive.ObjectID) {
+	if len(taskIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := taskCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": taskIDs}}); err != nil {
+		logger.ErrorLogger.Printf("Failed to remove tasks of a failed template instantiation: %v", err)
+	}
+}
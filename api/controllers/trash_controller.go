@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"api/middleware"
+	"api/models"
+	"api/utils"
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findTrashedTask loads a task from the user's trash. Only the owner, who is
+// the only one allowed to delete a task, can see it there.
+func findTrashedTask(ctx context.Context, taskID primitive.ObjectID, userID string) (*models.Task, error) {
+	var task models.Task
+	err := taskCollection.FindOne(ctx, bson.M{
+		"_id":        taskID,
+		"user_id":    userID,
+		"deleted_at": bson.M{"$exists": true},
+	}).Decode(&task)
+	return &task, err
+}
+
+// trashedIDs returns a trashed task's ID together with the subtasks deleted along with it
+func trashedIDs(ctx context.Context, taskID primitive.ObjectID) ([]string, error) {
+	cursor, err := taskCollection.Find(ctx,
+		bson.M{"trashed_with": taskID.Hex()},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtasks []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &subtasks); err != nil {
+		return nil, err
+	}
+
+	ids := []string{taskID.Hex()}
+	for _, subtask := range subtasks {
+		ids = append(ids, subtask.ID.Hex())
+	}
+	return ids, nil
+}
+
+// purgeTasks permanently deletes tasks with their comments, attachments,
+// revisions and dependency edges. The activity log is kept as an audit trail.
+func purgeTasks(ctx context.Context, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	if _, err := taskCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": toObjectIDs(taskIDs)}}); err != nil {
+		return err
+	}
+	if _, err := commentCollection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}}); err != nil {
+		return err
+	}
+	if err := attachmentService.DeleteForTasks(ctx, taskIDs); err != nil {
+		return err
+	}
+	if err := revisionRepo.DeleteByTasks(ctx, taskIDs); err != nil {
+		return err
+	}
+	return removeDependencyEdges(ctx, taskIDs)
+}
+
+// PurgeTrashedBefore permanently deletes every task that was moved to the trash
+// before the cutoff and returns how many were removed. It backs the trash
+// retention job.
+func PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	cursor, err := taskCollection.Find(ctx,
+		bson.M{"deleted_at": bson.M{"$lt": cutoff}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var tasks []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return 0, err
+	}
+
+	taskIDs := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID.Hex())
+	}
+	if err = purgeTasks(ctx, taskIDs); err != nil {
+		return 0, err
+	}
+	return len(taskIDs), nil
+}
+
+// GetTrash lists the tasks the user deleted, most recent first. Subtasks that
+// were deleted together with their parent are restored and purged with it, so
+// they are not listed on their own.
+func GetTrash(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	params := utils.GetPaginationFromRequest(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"user_id":      userClaims.ID,
+		"deleted_at":   bson.M{"$exists": true},
+		"trashed_with": bson.M{"$exists": false},
+	}
+
+	total, err := taskCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch trash", http.StatusInternalServerError)
+		return
+	}
+
+	cursor, err := taskCollection.Find(ctx, filter, options.Find().
+		SetSort(bson.M{"deleted_at": -1}).
+		SetSkip((params.Page-1)*params.Limit).
+		SetLimit(params.Limit))
+	if err != nil {
+		utils.SendError(w, "Failed to fetch trash", http.StatusInternalServerError)
+		return
+	}
+
+	tasks := make([]models.Task, 0)
+	if err = cursor.All(ctx, &tasks); err != nil {
+		utils.SendError(w, "Failed to decode trash", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, map[string]any{
+		"tasks":       tasks,
+		"total":       total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": utils.CalculateTotalPages(total, params.Limit),
+	})
+}
+
+// RestoreTask takes a task and the subtasks deleted with it out of the trash.
+// A task whose parent is still trashed or gone comes back as a top-level task.
+func RestoreTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	task, err := findTrashedTask(ctx, taskID, userClaims.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.SendError(w, "Task not found in trash", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to fetch task", http.StatusInternalServerError)
+		return
+	}
+
+	restore := bson.M{
+		"$set":   bson.M{"updated_at": time.Now()},
+		"$unset": bson.M{"deleted_at": "", "deleted_by": "", "trashed_with": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+
+	if task.ParentID != "" {
+		parentID, _ := primitive.ObjectIDFromHex(task.ParentID)
+		parent, err := getTaskByID(ctx, parentID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			utils.SendError(w, "Failed to fetch parent task", http.StatusInternalServerError)
+			return
+		}
+		if err == mongo.ErrNoDocuments || parent.DeletedAt != nil {
+			restore["$unset"].(bson.M)["parent_id"] = ""
+			task.ParentID = ""
+		}
+	}
+
+	if _, err = taskCollection.UpdateOne(ctx, bson.M{"_id": taskID}, restore); err != nil {
+		utils.SendError(w, "Failed to restore task", http.StatusInternalServerError)
+		return
+	}
+	if _, err = taskCollection.UpdateMany(ctx, bson.M{"trashed_with": taskID.Hex()}, bson.M{
+		"$unset": bson.M{"deleted_at": "", "deleted_by": "", "trashed_with": ""},
+		"$inc":   bson.M{"version": 1},
+	}); err != nil {
+		utils.SendError(w, "Failed to restore subtasks", http.StatusInternalServerError)
+		return
+	}
+
+	// An open task coming back reopens a completed parent
+	if err = rollUpParentStatus(ctx, task.ParentID); err != nil {
+		utils.SendError(w, "Failed to update parent task", http.StatusInternalServerError)
+		return
+	}
+
+	restored, err := getTaskByID(ctx, taskID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch restored task", http.StatusInternalServerError)
+		return
+	}
+
+	recordActivity(ctx, restored, userClaims.ID, models.ActivityTaskRecovered, nil)
+
+	utils.SendJSON(w, restored)
+}
+
+// PurgeTask permanently deletes a trashed task and the subtasks deleted with it
+func PurgeTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid task ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	task, err := findTrashedTask(ctx, taskID, userClaims.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.SendError(w, "Task not found in trash", http.StatusNotFound)
+			return
+		}
+		utils.SendError(w, "Failed to fetch task", http.StatusInternalServerError)
+		return
+	}
+
+	taskIDs, err := trashedIDs(ctx, taskID)
+	if err != nil {
+		utils.SendError(w, "Failed to fetch subtasks", http.StatusInternalServerError)
+		return
+	}
+	if err = purgeTasks(ctx, taskIDs); err != nil {
+		utils.SendError(w, "Failed to purge task", http.StatusInternalServerError)
+		return
+	}
+
+	recordActivity(ctx, task, userClaims.ID, models.ActivityTaskPurged, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,22 +1,26 @@
 package controllers
 
 import (
+	"api/logger"
 	"api/middleware"
 	"api/models"
 	"api/services"
 	"api/utils"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserController struct {
-	service *services.UserService
+	service     *services.UserService
+	invitations *services.InvitationService
 }
 
-func NewUserController(service *services.UserService) *UserController {
-	return &UserController{service: service}
+func NewUserController(service *services.UserService, invitations *services.InvitationService) *UserController {
+	return &UserController{service: service, invitations: invitations}
 }
 
 func (c *UserController) SignUp(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +127,12 @@ func (c *UserController) UpdateMe(w http.ResponseWriter, r *http.Request) {
 		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	for field := range updateData {
+		if !slices.Contains(models.EditableUserFields, field) {
+			utils.SendError(w, fmt.Sprintf("%s cannot be updated here", field), http.StatusBadRequest)
+			return
+		}
+	}
 
 	if err := c.service.UpdateUser(r.Context(), userID, updateData); err != nil {
 		utils.SendError(w, err.Error(), http.StatusInternalServerError)
@@ -172,10 +182,16 @@ func (c *UserController) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := c.service.VerifyEmail(r.Context(), token); err != nil {
+	user, err := c.service.VerifyEmail(r.Context(), token)
+	if err != nil {
 		utils.SendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	// Invitations sent to the address are handed over once the user has shown it is theirs
+	if err := c.invitations.ClaimPendingInvitations(r.Context(), user.ID.Hex(), user.Email, addInvitedCollaborator); err != nil {
+		logger.ErrorLogger.Printf("Failed to claim invitations for %s: %v", user.ID.Hex(), err)
+	}
+
 	utils.SendJSON(w, map[string]string{"message": "Email verified successfully"})
 }
\ No newline at end of file
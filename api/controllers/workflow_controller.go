@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"api/configs"
+	apperrors "api/errors"
+	"api/middleware"
+	"api/models"
+	"api/repositories"
+	"api/services"
+	"api/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var workflowService = services.NewWorkflowService(
+	repositories.NewWorkflowRepository(configs.GetCollection(configs.DB, "workflows")),
+	services.NewProjectService(projectRepo, userRepo),
+	workflowTasks{},
+)
+
+// workflowTasks gives the workflow service access to the tasks a workflow governs
+type workflowTasks struct{}
+
+// tasksInScope matches the tasks a workflow governs
+func tasksInScope(ownerID, projectID string) bson.M {
+	if projectID != "" {
+		return bson.M{"project_id": projectID}
+	}
+	return bson.M{"user_id": ownerID, "project_id": bson.M{"$exists": false}}
+}
+
+func (workflowTasks) CountOutside(ctx context.Context, ownerID, projectID string, statuses []string) (int64, error) {
+	filter := tasksInScope(ownerID, projectID)
+	filter["status"] = bson.M{"$nin": statuses}
+	return taskCollection.CountDocuments(ctx, filter)
+}
+
+// SyncCompletion bumps the version of every task it changes, so clients
+// holding an older ETag see the new completed_at
+func (workflowTasks) SyncCompletion(ctx context.Context, ownerID, projectID string, doneStatuses []string) error {
+	done := tasksInScope(ownerID, projectID)
+	done["status"] = bson.M{"$in": doneStatuses}
+	done["completed_at"] = bson.M{"$exists": false}
+	if _, err := taskCollection.UpdateMany(ctx, done, bson.M{
+		"$set": bson.M{"completed_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}); err != nil {
+		return err
+	}
+
+	open := tasksInScope(ownerID, projectID)
+	open["status"] = bson.M{"$nin": doneStatuses}
+	open["completed_at"] = bson.M{"$exists": true}
+	_, err := taskCollection.UpdateMany(ctx, open, bson.M{
+		"$unset": bson.M{"completed_at": ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	return err
+}
+
+// taskWorkflow returns the workflow that governs a task: its project's, or its owner's
+func taskWorkflow(ctx context.Context, task *models.Task) (*models.Workflow, error) {
+	return workflowService.Resolve(ctx, task.UserID, task.ProjectID)
+}
+
+// checkTransition rejects a status change the workflow does not allow
+func checkTransition(workflow *models.Workflow, from, to string) error {
+	if !workflow.HasStatus(to) {
+		return apperrors.NewValidationError(fmt.Sprintf("Unknown status %q", to), nil)
+	}
+	if !workflow.CanTransition(from, to) {
+		return apperrors.NewConflictError(fmt.Sprintf("Cannot move a task from %s to %s", from, to))
+	}
+	return nil
+}
+
+// doneStatusClauses returns one clause per workflow in scope, each matching the
+// tasks that workflow governs which are in one of its done statuses. A user's
+// own tasks can span their personal workflow and several project workflows.
+func doneStatusClauses(ctx context.Context, userID, projectID string) ([]bson.M, error) {
+	if projectID != "" {
+		workflow, err := workflowService.Resolve(ctx, "", projectID)
+		if err != nil {
+			return nil, err
+		}
+		return []bson.M{{"status": bson.M{"$in": workflow.DoneStatuses()}}}, nil
+	}
+
+	personal, err := workflowService.Resolve(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	clauses := []bson.M{{
+		"project_id": bson.M{"$exists": false},
+		"status":     bson.M{"$in": personal.DoneStatuses()},
+	}}
+
+	projectIDs, err := taskCollection.Distinct(ctx, "project_id", bson.M{
+		"user_id":    userID,
+		"project_id": bson.M{"$exists": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range projectIDs {
+		id, ok := value.(string)
+		if !ok {
+			continue
+		}
+		workflow, err := workflowService.Resolve(ctx, "", id)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, bson.M{
+			"project_id": id,
+			"status":     bson.M{"$in": workflow.DoneStatuses()},
+		})
+	}
+	return clauses, nil
+}
+
+// GetMyWorkflow returns the workflow of the user's personal tasks
+func GetMyWorkflow(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workflow, err := workflowService.Resolve(ctx, userClaims.ID, "")
+	if err != nil {
+		utils.SendError(w, "Failed to fetch workflow", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, workflow)
+}
+
+func UpdateMyWorkflow(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var request models.WorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workflow, err := workflowService.UpdateUserWorkflow(ctx, userClaims.ID, request)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, workflow)
+}
+
+func GetProjectWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workflow, err := workflowService.GetProjectWorkflow(ctx, projectID, userClaims.ID)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, workflow)
+}
+
+func UpdateProjectWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectID, err := utils.GetObjectIDFromRequest(r, "id")
+	if err != nil {
+		utils.SendError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	userClaims := r.Context().Value("user").(*middleware.UserClaims)
+
+	var request models.WorkflowRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.SendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workflow, err := workflowService.UpdateProjectWorkflow(ctx, projectID, userClaims.ID, request)
+	if err != nil {
+		utils.SendAppError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.SendJSON(w, workflow)
+}
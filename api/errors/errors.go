@@ -51,3 +51,27 @@ func NewInternalError(err error) *AppError {
 		InternalErr: err,
 	}
 }
+
+// NewForbiddenError creates a new forbidden error
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusForbidden,
+		Message: message,
+	}
+}
+
+// NewConflictError creates a new conflict error
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+}
+
+// NewPayloadTooLargeError creates a new payload too large error
+func NewPayloadTooLargeError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: message,
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// defaultAutoArchiveDays applies when AUTO_ARCHIVE_DAYS is not set
+const defaultAutoArchiveDays = 30
+
+// StartAutoArchiveJob archives tasks that were completed more than
+// AUTO_ARCHIVE_DAYS days ago. A task someone unarchived gets the same period
+// again from then on. Setting it to 0 turns auto-archiving off.
+func StartAutoArchiveJob() {
+	days := defaultAutoArchiveDays
+	if value := os.Getenv("AUTO_ARCHIVE_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid AUTO_ARCHIVE_DAYS %q, using %d", value, defaultAutoArchiveDays)
+		} else {
+			days = parsed
+		}
+	}
+	if days == 0 {
+		return
+	}
+
+	go func() {
+		for {
+			archiveCompletedTasks(time.Duration(days) * 24 * time.Hour)
+			time.Sleep(1 * time.Hour)
+		}
+	}()
+}
+
+func archiveCompletedTasks(age time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	cutoff := now.Add(-age)
+	result, err := taskCollection.UpdateMany(ctx, bson.M{
+		"completed_at": bson.M{"$lt": cutoff},
+		"archived_at":  bson.M{"$exists": false},
+		"deleted_at":   bson.M{"$exists": false},
+		"$or": []bson.M{
+			{"unarchived_at": bson.M{"$exists": false}},
+			{"unarchived_at": bson.M{"$lt": cutoff}},
+		},
+	}, bson.M{
+		"$set": bson.M{"archived_at": now},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		log.Printf("Error archiving completed tasks: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Archived %d completed tasks", result.ModifiedCount)
+	}
+}
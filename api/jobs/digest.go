@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"api/configs"
+	"api/models"
+	"api/notify"
+	"api/utils"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultDigestHour is the local hour digests go out when DIGEST_HOUR is not set
+const defaultDigestHour = 8
+
+// digestDateLayout is how the day of the last digest is stored on the user
+const digestDateLayout = "2006-01-02"
+
+// digestListLimit caps each section of a digest
+const digestListLimit = 20
+
+// digest is what one user's morning email lists
+type digest struct {
+	DueToday           []models.Task
+	Overdue            []models.Task
+	CompletedYesterday []models.Task
+	Comments           []models.Comment
+	TaskTitles         map[string]string
+}
+
+func (d *digest) empty() bool {
+	return len(d.DueToday) == 0 && len(d.Overdue) == 0 && len(d.CompletedYesterday) == 0 && len(d.Comments) == 0
+}
+
+// StartDailyDigestJob emails every user who opted into the daily digest once a
+// day, at DIGEST_HOUR (default 8) in their own timezone. The day of the last
+// digest is stored on the user, so a restart never sends a second one.
+func StartDailyDigestJob() {
+	hour := defaultDigestHour
+	if value := os.Getenv("DIGEST_HOUR"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 23 {
+			log.Printf("Invalid DIGEST_HOUR %q, using %d", value, defaultDigestHour)
+		} else {
+			hour = parsed
+		}
+	}
+
+	go func() {
+		for {
+			sendDailyDigests(hour)
+			time.Sleep(15 * time.Minute)
+		}
+	}()
+}
+
+func sendDailyDigests(hour int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	userCollection := configs.GetCollection(configs.DB, "users")
+	cursor, err := userCollection.Find(ctx, bson.M{
+		"preferences.daily_digest":         true,
+		"preferences.email_notifications":  true,
+		"preferences.notifications.digest": true,
+	})
+	if err != nil {
+		log.Printf("Error finding users for daily digest: %v", err)
+		return
+	}
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		log.Printf("Error decoding users for daily digest: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		location := user.Preferences.Location()
+		local := now.In(location)
+		today := local.Format(digestDateLayout)
+		if local.Hour() < hour || user.LastDigestDate == today {
+			continue
+		}
+
+		// Claim today's digest first so that no other run sends it again
+		result, err := userCollection.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "last_digest_date": bson.M{"$ne": today}},
+			bson.M{"$set": bson.M{"last_digest_date": today}},
+		)
+		if err != nil {
+			log.Printf("Error claiming daily digest for %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+
+		startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+		content, err := buildDigest(ctx, user.ID.Hex(), now, startOfDay)
+		if err != nil {
+			log.Printf("Error building daily digest for %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		// Comments are left out for users who turned comment notifications off
+		if !user.Preferences.Notifications.Comments {
+			content.Comments = nil
+		}
+		if content.empty() {
+			continue
+		}
+
+		subject := fmt.Sprintf("Your tasks for %s", local.Format("Monday, Jan 2"))
+		if err = notify.Email(&user, models.NotifyDigest, subject, renderDigest(content, location)); err != nil {
+			log.Printf("Error sending daily digest to %s: %v", user.ID.Hex(), err)
+			// Give the next run a chance to send it
+			userCollection.UpdateOne(ctx,
+				bson.M{"_id": user.ID, "last_digest_date": today},
+				bson.M{"$set": bson.M{"last_digest_date": user.LastDigestDate}},
+			)
+		}
+	}
+}
+
+// buildDigest collects what the user should hear about from the tasks they own
+// or collaborate on. Days are the user's local days.
+func buildDigest(ctx context.Context, userID string, now, startOfDay time.Time) (*digest, error) {
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+	startOfYesterday := startOfDay.AddDate(0, 0, -1)
+
+	visible := func(filter bson.M) bson.M {
+		filter["$or"] = []bson.M{
+			{"user_id": userID},
+			{"collaborators.user_id": userID},
+			{"collaborators": userID},
+		}
+		filter["deleted_at"] = bson.M{"$exists": false}
+		filter["archived_at"] = bson.M{"$exists": false}
+		return filter
+	}
+	find := func(filter bson.M, sort string) ([]models.Task, error) {
+		cursor, err := taskCollection.Find(ctx, visible(filter),
+			options.Find().SetSort(bson.M{sort: 1}).SetLimit(digestListLimit))
+		if err != nil {
+			return nil, err
+		}
+		var tasks []models.Task
+		err = cursor.All(ctx, &tasks)
+		return tasks, err
+	}
+
+	var content digest
+	var err error
+	// Tasks in any done status have completed_at set
+	if content.DueToday, err = find(bson.M{
+		"due_date":     bson.M{"$gte": startOfDay, "$lt": endOfDay},
+		"completed_at": bson.M{"$exists": false},
+	}, "due_date"); err != nil {
+		return nil, err
+	}
+	if content.Overdue, err = find(bson.M{
+		"due_date":     bson.M{"$lt": startOfDay},
+		"completed_at": bson.M{"$exists": false},
+	}, "due_date"); err != nil {
+		return nil, err
+	}
+	if content.CompletedYesterday, err = find(bson.M{
+		"completed_at": bson.M{"$gte": startOfYesterday, "$lt": startOfDay},
+	}, "completed_at"); err != nil {
+		return nil, err
+	}
+
+	// New comments by other people on the user's own tasks
+	owned, err := taskCollection.Find(ctx, bson.M{
+		"user_id":    userID,
+		"deleted_at": bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"_id": 1, "title": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var ownedTasks []models.Task
+	if err = owned.All(ctx, &ownedTasks); err != nil {
+		return nil, err
+	}
+	content.TaskTitles = make(map[string]string, len(ownedTasks))
+	taskIDs := make([]string, 0, len(ownedTasks))
+	for _, task := range ownedTasks {
+		content.TaskTitles[task.ID.Hex()] = task.Title
+		taskIDs = append(taskIDs, task.ID.Hex())
+	}
+	if len(taskIDs) > 0 {
+		commentCollection := configs.GetCollection(configs.DB, "comments")
+		cursor, err := commentCollection.Find(ctx, bson.M{
+			"task_id":    bson.M{"$in": taskIDs},
+			"user_id":    bson.M{"$ne": userID},
+			"created_at": bson.M{"$gte": now.Add(-24 * time.Hour)},
+			"deleted_at": bson.M{"$exists": false},
+		}, options.Find().SetSort(bson.M{"created_at": 1}).SetLimit(digestListLimit))
+		if err != nil {
+			return nil, err
+		}
+		if err = cursor.All(ctx, &content.Comments); err != nil {
+			return nil, err
+		}
+	}
+	return &content, nil
+}
+
+// renderDigest lays out the digest email. Titles and comments were escaped when
+// they were stored, like in the other emails.
+func renderDigest(content *digest, location *time.Location) string {
+	var b strings.Builder
+	b.WriteString("<p>Good morning,</p>")
+
+	section := func(title string, tasks []models.Task, when func(models.Task) string) {
+		if len(tasks) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "<h3>%s</h3><ul>", title)
+		for _, task := range tasks {
+			fmt.Fprintf(&b, "<li><strong>%s</strong> — %s</li>", task.Title, when(task))
+		}
+		b.WriteString("</ul>")
+	}
+	due := func(task models.Task) string {
+		return "due " + utils.FormatEmailDate(task.DueDate, location)
+	}
+	section("Due today", content.DueToday, due)
+	section("Overdue", content.Overdue, due)
+	section("Completed yesterday", content.CompletedYesterday, func(task models.Task) string {
+		return "completed " + utils.FormatEmailDate(*task.CompletedAt, location)
+	})
+
+	if len(content.Comments) > 0 {
+		b.WriteString("<h3>New comments</h3><ul>")
+		for _, comment := range content.Comments {
+			fmt.Fprintf(&b, "<li>On <strong>%s</strong> at %s: %s</li>",
+				content.TaskTitles[comment.TaskID],
+				utils.FormatEmailDate(comment.CreatedAt, location),
+				comment.Content)
+		}
+		b.WriteString("</ul>")
+	}
+
+	b.WriteString("<p>— Task Manager</p>")
+	return b.String()
+}
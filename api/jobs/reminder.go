@@ -3,6 +3,7 @@ package jobs
 import (
 	"api/configs"
 	"api/models"
+	"api/notify"
 	"api/utils"
 	"context"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var taskCollection = configs.GetCollection(configs.DB, "tasks")
@@ -23,68 +25,85 @@ func StartReminderJob() {
     }()
 }
 
+// checkReminders emails the owner of every open task with a reminder that has
+// come due. Reminders that come due together go out as one email, and each is
+// marked sent so it fires only once until the due date moves.
 func checkReminders() {
-    ctx := context.Background()
-    currentTime := time.Now()
-    oneHourFromNow := currentTime.Add(1 * time.Hour)
+	ctx := context.Background()
+	now := time.Now()
 
-    // Filter tasks due within the next hour and not yet reminded
-    filter := bson.M{
-        "due_date": bson.M{
-            "$gt":  currentTime,
-            "$lte": oneHourFromNow,
-        },
-        "status":             bson.M{"$ne": "Completed"},
-        "hour_reminder_sent": bson.M{"$ne": true},
-    }
+	filter := bson.M{
+		"due_date": bson.M{"$gt": now},
+		"reminders": bson.M{"$elemMatch": bson.M{
+			"remind_at": bson.M{"$lte": now},
+			"sent_at":   bson.M{"$exists": false},
+		}},
+		// Tasks in any done status have completed_at set
+		"completed_at": bson.M{"$exists": false},
+		"deleted_at":   bson.M{"$exists": false},
+	}
 
-    cursor, err := taskCollection.Find(ctx, filter)
-    if err != nil {
-        log.Printf("Error finding tasks for reminders: %v", err)
-        return
-    }
+	cursor, err := taskCollection.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Error finding tasks for reminders: %v", err)
+		return
+	}
 
-    var tasks []models.Task
-    if err := cursor.All(ctx, &tasks); err != nil {
-        log.Printf("Error decoding tasks: %v", err)
-        return
-    }
+	var tasks []models.Task
+	if err := cursor.All(ctx, &tasks); err != nil {
+		log.Printf("Error decoding tasks: %v", err)
+		return
+	}
 
-    for _, task := range tasks {
-        // Retrieve user email based on UserID
-        userCollection := configs.GetCollection(configs.DB, "users")
-        var user models.User
-        err := userCollection.FindOne(ctx, bson.M{"_id": task.UserID}).Decode(&user)
-        userEmail := user.Email
-        if err != nil {
-            log.Printf("Error retrieving user email: %v", err)
-            continue
-        }
+	userCollection := configs.GetCollection(configs.DB, "users")
+	for _, task := range tasks {
+		// Retrieve user email based on UserID
+		userID, err := primitive.ObjectIDFromHex(task.UserID)
+		if err != nil {
+			log.Printf("Invalid user ID on task %s: %v", task.ID.Hex(), err)
+			continue
+		}
+		var user models.User
+		if err := userCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+			log.Printf("Error retrieving user email: %v", err)
+			continue
+		}
 
-        subject := fmt.Sprintf("⏳ Reminder: Task '%s' Due Soon", task.Title)
-        body := fmt.Sprintf(`
+		subject := fmt.Sprintf("⏳ Reminder: Task '%s' Due Soon", task.Title)
+		body := fmt.Sprintf(`
             <p>Hi,</p>
             <p>Your task <strong>%s</strong> is due at <strong>%s</strong>.</p>
             <p><b>Description:</b> %s</p>
             <p>Please ensure to complete it on time.</p>
             <p>— Task Manager</p>
-        `, task.Title, task.DueDate.Format("Jan 2, 2006 15:04"), task.Description)
-
-        if err = utils.SendEmail(userEmail, subject, body); err != nil {
-            log.Printf("Error sending email: %v", err)
-            continue
-        }
+        `, task.Title, utils.FormatEmailDate(task.DueDate, user.Preferences.Location()), task.Description)
 
-        // Mark the reminder as sent
-        _, err = taskCollection.UpdateOne(
-            ctx,
-            bson.M{"_id": task.ID},
-            bson.M{"$set": bson.M{"hour_reminder_sent": true}},
-        )
-        if err != nil {
-            log.Printf("Error updating task reminder status: %v", err)
-        }
-    }
-}
+		// Reminders the user opted out of still count as sent
+		if err = notify.Email(&user, models.NotifyReminders, subject, body); err != nil {
+			log.Printf("Error sending email: %v", err)
+			continue
+		}
 
+		notification := models.TaskNotification(models.NotificationReminder, &task, "",
+			fmt.Sprintf("'%s' is due at %s", task.Title, utils.FormatEmailDate(task.DueDate, user.Preferences.Location())))
+		if err = notify.Record(ctx, []string{task.UserID}, notification); err != nil {
+			log.Printf("Error recording reminder notification: %v", err)
+		}
 
+		// Mark the reminders as sent by position, so only while the list is still
+		// the one read; an edit or a moved due date in between leaves it alone
+		sent := bson.M{}
+		for i, reminder := range task.Reminders {
+			if reminder.SentAt == nil && !reminder.RemindAt.After(now) {
+				sent[fmt.Sprintf("reminders.%d.sent_at", i)] = now
+			}
+		}
+		_, err = taskCollection.UpdateOne(ctx,
+			bson.M{"_id": task.ID, "due_date": task.DueDate, "reminders": task.Reminders},
+			bson.M{"$set": sent},
+		)
+		if err != nil {
+			log.Printf("Error updating task reminder status: %v", err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultTrashRetentionDays applies when TRASH_RETENTION_DAYS is not set
+const defaultTrashRetentionDays = 30
+
+// trashRetention reads how long deleted tasks stay in the trash
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days < 1 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartTrashPurgeJob empties trash older than the retention period once an
+// hour. The purge itself is passed in so the job does not have to know which
+// collections hang off a task.
+func StartTrashPurgeJob(purge func(ctx context.Context, cutoff time.Time) (int, error)) {
+	retention := trashRetention()
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			purged, err := purge(ctx, time.Now().Add(-retention))
+			cancel()
+			if err != nil {
+				log.Printf("Error purging trash: %v", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d tasks from the trash", purged)
+			}
+			time.Sleep(1 * time.Hour)
+		}
+	}()
+}
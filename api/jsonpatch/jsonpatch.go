@@ -0,0 +1,256 @@
+// Package jsonpatch applies RFC 6902 JSON Patch and RFC 7396 JSON Merge Patch
+// documents to decoded JSON values.
+package jsonpatch
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// PatchOperation is a single RFC 6902 JSON Patch operation
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// ApplyMergePatch applies an RFC 7396 merge patch to a decoded JSON document.
+// Objects are merged recursively, null removes a member, and anything else
+// replaces the target outright.
+func ApplyMergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	result := make(map[string]any, len(targetObject))
+	for key, value := range targetObject {
+		result[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = ApplyMergePatch(result[key], value)
+	}
+	return result
+}
+
+// ApplyJSONPatch applies RFC 6902 operations to a decoded JSON document. The
+// operations apply in order and the first failure aborts the whole patch.
+func ApplyJSONPatch(doc any, operations []PatchOperation) (any, error) {
+	var err error
+	for i, operation := range operations {
+		switch operation.Op {
+		case "add":
+			doc, err = addValue(doc, operation.Path, operation.Value)
+		case "remove":
+			doc, _, err = removeValue(doc, operation.Path)
+		case "replace":
+			if doc, _, err = removeValue(doc, operation.Path); err == nil {
+				doc, err = addValue(doc, operation.Path, operation.Value)
+			}
+		case "move":
+			if strings.HasPrefix(operation.Path, operation.From+"/") {
+				err = fmt.Errorf("cannot move %s into itself", operation.From)
+				break
+			}
+			var value any
+			if doc, value, err = removeValue(doc, operation.From); err == nil {
+				doc, err = addValue(doc, operation.Path, value)
+			}
+		case "copy":
+			var value any
+			if value, err = getValue(doc, operation.From); err == nil {
+				doc, err = addValue(doc, operation.Path, deepCopy(value))
+			}
+		case "test":
+			var value any
+			if value, err = getValue(doc, operation.Path); err == nil && !reflect.DeepEqual(value, operation.Value) {
+				err = fmt.Errorf("test failed for %s", operation.Path)
+			}
+		default:
+			err = fmt.Errorf("unknown operation %q", operation.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return doc, nil
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex resolves an array token; "-" is one past the end and only
+// allowed when appending
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	limit := length - 1
+	if appending {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func getValue(doc any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", pointer)
+			}
+			current = value
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+	}
+	return current, nil
+}
+
+// addValue returns doc with value added at pointer. Objects are updated in
+// place; a changed array is written back over the old one in its parent.
+func addValue(doc any, pointer string, value any) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(doc, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return doc, nil
+	case []any:
+		index, err := arrayIndex(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		updated := append(node[:index:index], append([]any{value}, node[index:]...)...)
+		return replaceArray(doc, parentPointer, updated)
+	default:
+		return nil, fmt.Errorf("path %s does not exist", parentPointer)
+	}
+}
+
+// removeValue returns doc without the value at pointer, and the removed value
+func removeValue(doc any, pointer string) (any, any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(doc, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		value, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %s does not exist", pointer)
+		}
+		delete(node, last)
+		return doc, value, nil
+	case []any:
+		index, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := node[index]
+		updated := append(node[:index:index], node[index+1:]...)
+		doc, err = replaceArray(doc, parentPointer, updated)
+		return doc, value, err
+	default:
+		return nil, nil, fmt.Errorf("path %s does not exist", pointer)
+	}
+}
+
+// replaceArray writes a rebuilt array back over the one at pointer
+func replaceArray(doc any, pointer string, array []any) (any, error) {
+	if pointer == "" {
+		return array, nil
+	}
+	doc, _, err := removeValue(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+	return addValue(doc, pointer, array)
+}
+
+func deepCopy(value any) any {
+	switch node := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(node))
+		for key, child := range node {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(node))
+		for i, child := range node {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,158 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, document string) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("invalid JSON %s: %v", document, err)
+	}
+	return value
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc        string
+		operations string
+		want       string
+		wantErr    bool
+	}{
+		// add
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`, false},
+		{"add replaces existing member", `{"a":1}`, `[{"op":"add","path":"/a","value":2}]`, `{"a":2}`, false},
+		{"add nested member", `{"a":{}}`, `[{"op":"add","path":"/a/b","value":"c"}]`, `{"a":{"b":"c"}}`, false},
+		{"add null value", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`, false},
+		{"add inserts into array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`, false},
+		{"add at array length", `{"a":[1]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2]}`, false},
+		{"add dash appends", `{"a":[1,2]}`, `[{"op":"add","path":"/a/-","value":3}]`, `{"a":[1,2,3]}`, false},
+		{"add dash to empty array", `{"a":[]}`, `[{"op":"add","path":"/a/-","value":1}]`, `{"a":[1]}`, false},
+		{"add to nested array", `{"a":[[1]]}`, `[{"op":"add","path":"/a/0/-","value":2}]`, `{"a":[[1,2]]}`, false},
+		{"add replaces root", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`, false},
+		{"add past array end", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`, "", true},
+		{"add to missing parent", `{}`, `[{"op":"add","path":"/a/b","value":1}]`, "", true},
+		{"add with leading zero index", `{"a":[1,2]}`, `[{"op":"add","path":"/a/01","value":3}]`, "", true},
+		{"add with negative index", `{"a":[1]}`, `[{"op":"add","path":"/a/-1","value":3}]`, "", true},
+		{"path without leading slash", `{}`, `[{"op":"add","path":"a","value":1}]`, "", true},
+
+		// remove
+		{"remove member", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`, false},
+		{"remove array element", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`, false},
+		{"remove last array element", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1]}`, false},
+		{"remove missing member", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, "", true},
+		{"remove past array end", `{"a":[1]}`, `[{"op":"remove","path":"/a/1"}]`, "", true},
+		{"remove with dash", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, "", true},
+
+		// replace
+		{"replace member", `{"a":1}`, `[{"op":"replace","path":"/a","value":"x"}]`, `{"a":"x"}`, false},
+		{"replace array element", `{"a":[1,2,3]}`, `[{"op":"replace","path":"/a/1","value":9}]`, `{"a":[1,9,3]}`, false},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`, false},
+		{"replace missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, "", true},
+		{"replace with dash", `{"a":[1]}`, `[{"op":"replace","path":"/a/-","value":2}]`, "", true},
+
+		// move
+		{"move member", `{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`, false},
+		{"move between objects", `{"a":{"x":1},"b":{}}`, `[{"op":"move","from":"/a/x","path":"/b/y"}]`, `{"a":{},"b":{"y":1}}`, false},
+		{"move within array", `{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/2"}]`, `{"a":[2,3,1]}`, false},
+		{"move to array end", `{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/-"}]`, `{"a":[2,3,1]}`, false},
+		{"move into itself", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b"}]`, "", true},
+		{"move missing member", `{}`, `[{"op":"move","from":"/a","path":"/b"}]`, "", true},
+
+		// copy
+		{"copy member", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`, false},
+		{"copy into array", `{"a":[1,2]}`, `[{"op":"copy","from":"/a/0","path":"/a/-"}]`, `{"a":[1,2,1]}`, false},
+		{"copy is independent", `{"a":{"b":1}}`,
+			`[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			`{"a":{"b":1},"c":{"b":2}}`, false},
+		{"copy missing member", `{}`, `[{"op":"copy","from":"/a","path":"/b"}]`, "", true},
+
+		// test
+		{"test matching value", `{"a":{"b":[1,"x"]}}`, `[{"op":"test","path":"/a","value":{"b":[1,"x"]}}]`, `{"a":{"b":[1,"x"]}}`, false},
+		{"test array element", `{"a":[1,2]}`, `[{"op":"test","path":"/a/1","value":2}]`, `{"a":[1,2]}`, false},
+		{"test other value", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, "", true},
+		{"test other type", `{"a":1}`, `[{"op":"test","path":"/a","value":"1"}]`, "", true},
+		{"test missing member", `{}`, `[{"op":"test","path":"/a","value":null}]`, "", true},
+
+		// escaping
+		{"slash escaped as ~1", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`, false},
+		{"tilde escaped as ~0", `{"m~n":1}`, `[{"op":"remove","path":"/m~0n"}]`, `{}`, false},
+		{"~01 is a literal ~1", `{}`, `[{"op":"add","path":"/~01","value":1}]`, `{"~1":1}`, false},
+		{"empty member name", `{}`, `[{"op":"add","path":"/","value":1}]`, `{"":1}`, false},
+
+		// sequencing
+		{"operations apply in order", `{"a":1}`,
+			`[{"op":"add","path":"/b","value":2},{"op":"move","from":"/b","path":"/c"},{"op":"test","path":"/c","value":2}]`,
+			`{"a":1,"c":2}`, false},
+		{"failed test aborts the patch", `{"a":1}`,
+			`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, "", true},
+		{"unknown operation", `{}`, `[{"op":"merge","path":"/a","value":1}]`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []PatchOperation
+			if err := json.Unmarshal([]byte(tt.operations), &operations); err != nil {
+				t.Fatalf("invalid operations: %v", err)
+			}
+
+			got, err := ApplyJSONPatch(decode(t, tt.doc), operations)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// The cases follow the examples in RFC 7396 appendix A
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null deletes member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null deletes only that member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"null for missing member", `{"a":"b"}`, `{"x":null}`, `{"a":"b"}`},
+		{"array replaced by value", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value replaced by array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested merge with deletion", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"arrays are replaced whole", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"array target", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"array patch", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"null patch", `{"a":"foo"}`, `null`, `null`},
+		{"string patch", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"existing null kept", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"object patch on array", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"nulls deep in a new member", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := decode(t, tt.target)
+			got := ApplyMergePatch(target, decode(t, tt.patch))
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(target, decode(t, tt.target)) {
+				t.Errorf("target was modified: %v", target)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"api/controllers"
 	"api/jobs"
 	"api/middleware"
+	"api/notify"
 	"api/repositories"
 	"api/routes"
 	"api/services"
@@ -21,26 +22,50 @@ import (
 func main() {
 	// Initialize repositories, services, controllers
 	userRepo := repositories.NewUserRepository(configs.GetCollection(configs.DB, "users"))
+	invitationRepo := repositories.NewInvitationRepository(configs.GetCollection(configs.DB, "invitations"))
+	invitationService := services.NewInvitationService(invitationRepo, userRepo)
+	invitationController := controllers.NewInvitationController(invitationService)
+
 	userService := services.NewUserService(userRepo)
-	userController := controllers.NewUserController(userService)
+	userController := controllers.NewUserController(userService, invitationService)
 
-	profileService := services.NewProfileService(userRepo)
+	profileService := services.NewProfileService(userRepo, configs.Store)
 	profileController := controllers.NewProfileController(profileService)
 
+	notificationService := services.NewNotificationService(notify.Inbox)
+	notificationController := controllers.NewNotificationController(notificationService)
+
+	projectRepo := repositories.NewProjectRepository(configs.GetCollection(configs.DB, "projects"))
+	projectService := services.NewProjectService(projectRepo, userRepo)
+	projectController := controllers.NewProjectController(projectService)
+
 	// Connect to MongoDB
 	configs.ConnectDB()
 
 	// Seed Tag 
 	utils.SeedTags()
+	if err := utils.BackfillCompletedAt(); err != nil {
+		log.Printf("Failed to backfill completion times: %v", err)
+	}
+	if err := utils.BackfillReminders(); err != nil {
+		log.Printf("Failed to backfill reminders: %v", err)
+	}
+	if err := utils.BackfillNotificationSettings(); err != nil {
+		log.Printf("Failed to backfill notification settings: %v", err)
+	}
+	if err := controllers.EnsureRevisionIndexes(); err != nil {
+		log.Printf("Failed to create revision indexes: %v", err)
+	}
 
 	// Start background jobs
 	jobs.StartReminderJob()
+	jobs.StartDailyDigestJob()
+	jobs.StartTrashPurgeJob(controllers.PurgeTrashedBefore)
+	jobs.StartAutoArchiveJob()
 
 	// Create router
 	r := mux.NewRouter()
 
-    // Add static file server for uploads
-    r.PathPrefix("/api/uploads/").Handler(http.StripPrefix("/api/uploads/", http.FileServer(http.Dir("uploads"))))
 	// Add middlewares
 	r.Use(middleware.RateLimit)
 	r.Use(middleware.SanitizeInput)
@@ -49,12 +74,18 @@ func main() {
 	// Register your routes
 	routes.RegisterUserRoutes(r, userController, profileController)
 	routes.RegisterTaskRoutes(r)
+	routes.RegisterProjectRoutes(r, projectController)
+	routes.RegisterInvitationRoutes(r, invitationController)
+	routes.RegisterNotificationRoutes(r, notificationController)
+	routes.RegisterWorkflowRoutes(r)
+	routes.RegisterTemplateRoutes(r)
 
 	// Setup CORS
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000"}, // Allow frontend origin
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 	})
 
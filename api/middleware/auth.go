@@ -13,6 +13,8 @@ import (
 type UserClaims struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
+	// Purpose is only set on emailed link tokens, which are never sessions
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -30,9 +32,11 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
 			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
-		if err != nil || !token.Valid {
+		// Emailed link tokens share the key but carry an audience or purpose;
+		// session tokens have neither
+		if err != nil || !token.Valid || claims.ID == "" || claims.Purpose != "" || len(claims.Audience) > 0 {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActivityTaskCreated         = "task_created"
+	ActivitySubtaskAdded        = "subtask_added"
+	ActivityTaskUpdated         = "task_updated"
+	ActivityStatusChanged       = "status_changed"
+	ActivityTaskRestored        = "task_restored"
+	ActivityTaskDeleted         = "task_deleted"
+	ActivityTaskRecovered       = "task_recovered"
+	ActivityTaskPurged          = "task_purged"
+	ActivityTaskArchived        = "task_archived"
+	ActivityTaskUnarchived      = "task_unarchived"
+	ActivityCollaboratorAdded   = "collaborator_added"
+	ActivityCollaboratorRemoved = "collaborator_removed"
+	ActivityCommentAdded        = "comment_added"
+	ActivityCommentEdited       = "comment_edited"
+	ActivityCommentDeleted      = "comment_deleted"
+)
+
+// FieldChange is the before and after value of one field touched by a mutation
+type FieldChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before any    `json:"before" bson:"before"`
+	After  any    `json:"after" bson:"after"`
+}
+
+// Activity records who did what to a task and when. Events outlive the task so
+// that deletions still show up in the actor's feed.
+type Activity struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TaskID    string             `json:"task_id" bson:"task_id"`
+	TaskTitle string             `json:"task_title" bson:"task_title"`
+	ActorID   string             `json:"actor_id" bson:"actor_id"`
+	Action    string             `json:"action" bson:"action"`
+	CommentID string             `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	Changes   []FieldChange      `json:"changes,omitempty" bson:"changes,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// change appends a FieldChange when before and after differ
+func change(changes []FieldChange, field string, before, after any) []FieldChange {
+	if reflect.DeepEqual(before, after) {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, Before: before, After: after})
+}
+
+// DiffTasks lists the user-editable fields that differ between two versions of a task
+func DiffTasks(before, after *Task) []FieldChange {
+	var changes []FieldChange
+	changes = change(changes, "title", before.Title, after.Title)
+	changes = change(changes, "description", before.Description, after.Description)
+	if !before.DueDate.Equal(after.DueDate) {
+		changes = append(changes, FieldChange{Field: "due_date", Before: before.DueDate, After: after.DueDate})
+	}
+	changes = change(changes, "priority", before.Priority, after.Priority)
+	changes = change(changes, "status", before.Status, after.Status)
+	changes = change(changes, "tags", nonNilStrings(before.Tags), nonNilStrings(after.Tags))
+	changes = change(changes, "project_id", before.ProjectID, after.ProjectID)
+	changes = change(changes, "recurrence", before.Recurrence, after.Recurrence)
+	if !sameReminders(before.Reminders, after.Reminders) {
+		changes = append(changes, FieldChange{Field: "reminders", Before: before.Reminders, After: after.Reminders})
+	}
+	return changes
+}
+
+// sameReminders compares reminder schedules, ignoring their sent state
+func sameReminders(before, after []Reminder) bool {
+	if len(before) != len(after) {
+		return false
+	}
+	for i := range before {
+		if !before[i].sameSchedule(&after[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
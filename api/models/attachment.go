@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxAttachmentSize is the largest file that can be attached to a task
+const MaxAttachmentSize = 10 << 20
+
+// Attachment is a file uploaded to a task. The content lives in file storage
+// under StorageKey; this document only holds its metadata.
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TaskID      string             `json:"task_id" bson:"task_id"`
+	UploaderID  string             `json:"uploader_id" bson:"uploader_id"`
+	FileName    string             `json:"file_name" bson:"file_name"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	StorageKey  string             `json:"-" bson:"storage_key"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// rankDigits are the characters a rank is built from, in sort order
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// RankBetween returns a rank that sorts strictly between a and b. An empty a
+// means the start of the column and an empty b its end, so a task can be
+// placed anywhere without renumbering its neighbours.
+func RankBetween(a, b string) string {
+	var prefix strings.Builder
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = strings.IndexByte(rankDigits, a[i])
+		}
+		hi := len(rankDigits)
+		if b != "" && i < len(b) {
+			hi = strings.IndexByte(rankDigits, b[i])
+		}
+
+		if lo == hi {
+			prefix.WriteByte(rankDigits[lo])
+			continue
+		}
+		if hi-lo > 1 {
+			prefix.WriteByte(rankDigits[(lo+hi)/2])
+			return prefix.String()
+		}
+		// No room at this position: keep a's digit and go past the rest of a
+		prefix.WriteByte(rankDigits[lo])
+		rest := ""
+		if i+1 < len(a) {
+			rest = a[i+1:]
+		}
+		return prefix.String() + RankBetween(rest, "")
+	}
+}
+
+type BoardColumn struct {
+	Status string `json:"status"`
+	Done   bool   `json:"done"`
+	Tasks  []Task `json:"tasks"`
+}
+
+type Board struct {
+	ProjectID string        `json:"project_id,omitempty"`
+	Workflow  *Workflow     `json:"workflow"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// MoveTaskRequest places a task in a column; Position is its zero-based index
+// among the column's other tasks, and a missing position means the end
+type MoveTaskRequest struct {
+	Status   string `json:"status"`
+	Position *int   `json:"position"`
+}
@@ -0,0 +1,55 @@
+package models
+
+// Operations a bulk request can apply
+const (
+	BulkSetStatus    = "set_status"
+	BulkSetPriority  = "set_priority"
+	BulkAddTags      = "add_tags"
+	BulkRemoveTags   = "remove_tags"
+	BulkShiftDueDate = "shift_due_date"
+	BulkArchive      = "archive"
+	BulkDelete       = "delete"
+)
+
+// MaxBulkTasks caps how many tasks one bulk request may touch
+const MaxBulkTasks = 100
+
+// BulkTaskFilter selects tasks with the same parameters GET /api/tasks takes
+type BulkTaskFilter struct {
+	ProjectID string `json:"project_id"`
+	Search    string `json:"search"`
+	Priority  string `json:"priority"`
+	Status    string `json:"status"`
+	TopLevel  bool   `json:"top_level"`
+	Archived  bool   `json:"archived"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// BulkTaskRequest applies one operation to the listed tasks or to the tasks
+// matching a filter. Only the fields the operation needs are read.
+type BulkTaskRequest struct {
+	TaskIDs   []string        `json:"task_ids"`
+	Filter    *BulkTaskFilter `json:"filter"`
+	Operation string          `json:"operation"`
+	Status    string          `json:"status"`
+	Priority  string          `json:"priority"`
+	Tags      []string        `json:"tags"`
+	ShiftDays int             `json:"shift_days"`
+	// Subtasks is how delete handles subtasks: delete (default) or detach
+	Subtasks string `json:"subtasks"`
+}
+
+type BulkTaskResult struct {
+	TaskID  string `json:"task_id"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BulkTaskResponse struct {
+	Operation string           `json:"operation"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkTaskResult `json:"results"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Roles a user can hold on a task. The owner role is implied by Task.UserID
+// and is never stored on a collaborator entry.
+const (
+	RoleOwner     = "owner"
+	RoleEditor    = "editor"
+	RoleCommenter = "commenter"
+	RoleViewer    = "viewer"
+)
+
+// TaskAction is something a user may try to do with a task
+type TaskAction int
+
+const (
+	ActionView TaskAction = iota
+	ActionComment
+	ActionEdit
+	ActionManage
+)
+
+type Collaborator struct {
+	UserID  string    `json:"user_id" bson:"user_id"`
+	Role    string    `json:"role" bson:"role"`
+	AddedAt time.Time `json:"added_at" bson:"added_at"`
+}
+
+// UnmarshalBSONValue also accepts the bare user ID strings that collaborators
+// used to be stored as; those entries are treated as viewers.
+func (c *Collaborator) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	if t == bsontype.String {
+		c.UserID = raw.StringValue()
+		c.Role = RoleViewer
+		return nil
+	}
+
+	type collaborator Collaborator
+	return raw.Unmarshal((*collaborator)(c))
+}
+
+func IsValidCollaboratorRole(role string) bool {
+	switch role {
+	case RoleEditor, RoleCommenter, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// RoleAllows reports whether a role is permitted to perform an action
+func RoleAllows(role string, action TaskAction) bool {
+	switch role {
+	case RoleOwner:
+		return true
+	case RoleEditor:
+		return action <= ActionEdit
+	case RoleCommenter:
+		return action <= ActionComment
+	case RoleViewer:
+		return action == ActionView
+	}
+	return false
+}
+
+// RoleFor returns the user's role on the task, or an empty string if they have none
+func (t *Task) RoleFor(userID string) string {
+	if t.UserID == userID {
+		return RoleOwner
+	}
+	for _, collaborator := range t.Collaborators {
+		if collaborator.UserID == userID {
+			return collaborator.Role
+		}
+	}
+	return ""
+}
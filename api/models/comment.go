@@ -2,21 +2,66 @@ package models
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// mentionPattern matches @handles, where a handle is an email address, its
+// local part, or a user's name written without spaces.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.+-]+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+// DeletedCommentContent replaces the text of a deleted comment so replies keep their context
+const DeletedCommentContent = "[comment deleted]"
+
+// CommentRevision is a past version of a comment's content
+type CommentRevision struct {
+	Content  string    `json:"content" bson:"content"`
+	EditedBy string    `json:"edited_by" bson:"edited_by"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
+
+type Reaction struct {
+	Emoji     string    `json:"emoji" bson:"emoji"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
 type Comment struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	TaskID    string             `json:"task_id" bson:"task_id"`
 	UserID    string             `json:"user_id" bson:"user_id"`
+	ParentID  string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	ThreadID  string             `json:"thread_id,omitempty" bson:"thread_id,omitempty"`
 	Content   string             `json:"content" bson:"content"`
+	Mentions  []string           `json:"mentions,omitempty" bson:"mentions,omitempty"`
+	Reactions []Reaction         `json:"reactions,omitempty" bson:"reactions,omitempty"`
+	Revisions []CommentRevision  `json:"-" bson:"revisions,omitempty"`
+	EditedAt  *time.Time         `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	DeletedAt *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
+// CommentThread is a top-level comment together with every reply beneath it
+type CommentThread struct {
+	Comment
+	Replies []Comment `json:"replies"`
+}
+
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
 func (c *Comment) Validate() error {
 	c.Content = strings.TrimSpace(c.Content)
 	if len(c.Content) < 10 || len(c.Content) > 1000 {
@@ -24,3 +69,30 @@ func (c *Comment) Validate() error {
 	}
 	return nil
 }
+
+// MentionHandles returns the distinct @handles in the comment, lowercased
+func (c *Comment) MentionHandles() []string {
+	seen := make(map[string]bool)
+	handles := make([]string, 0)
+	for _, match := range mentionPattern.FindAllStringSubmatch(c.Content, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if handle != "" && !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// ValidateEmoji checks that a reaction is a short, single emoji-like token
+func ValidateEmoji(emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > 8 {
+		return errors.New("emoji must be between 1 and 8 characters")
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return errors.New("emoji must not contain letters, digits, or spaces")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// Invitation asks someone, by email address, to collaborate on a task. Invitees
+// without an account keep a pending invitation until they sign up.
+type Invitation struct {
+	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TaskID         string             `json:"task_id" bson:"task_id"`
+	TaskTitle      string             `json:"task_title" bson:"task_title"`
+	InviterID      string             `json:"inviter_id" bson:"inviter_id"`
+	Email          string             `json:"email" bson:"email"`
+	InviteeID      string             `json:"invitee_id,omitempty" bson:"invitee_id,omitempty"`
+	Role           string             `json:"role" bson:"role"`
+	Status         string             `json:"status" bson:"status"`
+	AcceptOnSignup bool               `json:"accept_on_signup" bson:"accept_on_signup"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	RespondedAt    *time.Time         `json:"responded_at,omitempty" bson:"responded_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (i *Invitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
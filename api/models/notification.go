@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of in-app notification
+const (
+	NotificationCollaboratorAdded   = "collaborator_added"
+	NotificationCollaboratorRemoved = "collaborator_removed"
+	NotificationRoleChanged         = "role_changed"
+	NotificationComment             = "comment"
+	NotificationMention             = "mention"
+	NotificationReminder            = "reminder"
+	NotificationStatusChanged       = "status_changed"
+)
+
+// Notification is an entry in a user's in-app inbox. It is unread until ReadAt
+// is set. Unlike email, every event is recorded whatever the user's email
+// settings are.
+type Notification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    string             `json:"user_id" bson:"user_id"`
+	Type      string             `json:"type" bson:"type"`
+	Message   string             `json:"message" bson:"message"`
+	TaskID    string             `json:"task_id,omitempty" bson:"task_id,omitempty"`
+	TaskTitle string             `json:"task_title,omitempty" bson:"task_title,omitempty"`
+	CommentID string             `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	ActorID   string             `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	ReadAt    *time.Time         `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// TaskNotification starts a notification about a task
+func TaskNotification(kind string, task *Task, actorID, message string) Notification {
+	return Notification{
+		Type:      kind,
+		Message:   message,
+		TaskID:    task.ID.Hex(),
+		TaskTitle: task.Title,
+		ActorID:   actorID,
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProjectMember struct {
+	UserID  string    `json:"user_id" bson:"user_id"`
+	Role    string    `json:"role" bson:"role"`
+	AddedAt time.Time `json:"added_at" bson:"added_at"`
+}
+
+type Project struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	OwnerID     string             `json:"owner_id" bson:"owner_id"`
+	Members     []ProjectMember    `json:"members" bson:"members"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type ProjectMemberRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (p *Project) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if len(p.Name) < 3 || len(p.Name) > 100 {
+		return errors.New("project name must be between 3 and 100 characters")
+	}
+
+	p.Description = strings.TrimSpace(p.Description)
+	if len(p.Description) > 1000 {
+		return errors.New("description cannot exceed 1000 characters")
+	}
+
+	if p.OwnerID == "" {
+		return errors.New("owner ID is required")
+	}
+	return nil
+}
+
+// HasMember reports whether the user belongs to the project, owner included
+func (p *Project) HasMember(userID string) bool {
+	for _, member := range p.Members {
+		if member.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// RecurrenceRule describes how a task repeats, loosely following RFC 5545 RRULEs.
+// Daily, weekly and monthly rules repeat every Interval days, weeks or months;
+// custom rules repeat every Interval hours.
+type RecurrenceRule struct {
+	Frequency  string     `json:"frequency" bson:"frequency"`
+	Interval   int        `json:"interval" bson:"interval"`
+	Until      *time.Time `json:"until,omitempty" bson:"until,omitempty"`
+	Count      int        `json:"count,omitempty" bson:"count,omitempty"`
+	Occurrence int        `json:"occurrence" bson:"occurrence"`
+}
+
+func (rr *RecurrenceRule) Validate() error {
+	rr.Frequency = strings.ToLower(strings.TrimSpace(rr.Frequency))
+	switch rr.Frequency {
+	case "daily", "weekly", "monthly":
+		if rr.Interval == 0 {
+			rr.Interval = 1
+		}
+	case "custom":
+		if rr.Interval == 0 {
+			return errors.New("custom recurrence requires an interval in hours")
+		}
+	default:
+		return errors.New("recurrence frequency must be daily, weekly, monthly, or custom")
+	}
+
+	if rr.Interval < 0 {
+		return errors.New("recurrence interval must be positive")
+	}
+	if rr.Count < 0 {
+		return errors.New("recurrence count cannot be negative")
+	}
+	if rr.Occurrence < 1 {
+		rr.Occurrence = 1
+	}
+	return nil
+}
+
+// addMonths moves t forward by months, clamping to the last day of the target
+// month instead of overflowing into the next one, so Jan 31 becomes Feb 28
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// advance returns the due date steps occurrences after from
+func (rr *RecurrenceRule) advance(from time.Time, steps int) time.Time {
+	switch rr.Frequency {
+	case "daily":
+		return from.AddDate(0, 0, steps*rr.Interval)
+	case "weekly":
+		return from.AddDate(0, 0, steps*7*rr.Interval)
+	case "monthly":
+		return addMonths(from, steps*rr.Interval)
+	default:
+		return from.Add(time.Duration(steps*rr.Interval) * time.Hour)
+	}
+}
+
+// NextDueDate moves dueDate forward until it lands after now. The second return
+// value is false once the rule's end conditions have been reached. Each step is
+// counted from dueDate, so skipping a short month doesn't pull later dates back.
+func (rr *RecurrenceRule) NextDueDate(dueDate, now time.Time) (time.Time, bool) {
+	if rr.Count > 0 && rr.Occurrence >= rr.Count {
+		return time.Time{}, false
+	}
+
+	steps := 1
+	next := rr.advance(dueDate, steps)
+	for !next.After(now) {
+		steps++
+		next = rr.advance(dueDate, steps)
+	}
+
+	if rr.Until != nil && next.After(*rr.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxTaskReminders caps how many reminders a single task can carry
+const MaxTaskReminders = 10
+
+// Reminder is one reminder for a task: either a number of minutes before the
+// due date or an absolute time. RemindAt and SentAt are maintained by the
+// server; each reminder is sent once and re-armed when the due date moves.
+type Reminder struct {
+	OffsetMinutes int        `json:"offset_minutes,omitempty" bson:"offset_minutes,omitempty"`
+	At            *time.Time `json:"at,omitempty" bson:"at,omitempty"`
+	RemindAt      time.Time  `json:"remind_at" bson:"remind_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+}
+
+// DefaultReminders is what a task gets when it is created without any: one
+// reminder an hour before it is due
+func DefaultReminders() []Reminder {
+	return []Reminder{{OffsetMinutes: 60}}
+}
+
+func (r *Reminder) Validate() error {
+	if (r.OffsetMinutes != 0) == (r.At != nil) {
+		return errors.New("a reminder needs either offset_minutes or at")
+	}
+	if r.OffsetMinutes < 0 {
+		return errors.New("reminder offsets cannot be negative")
+	}
+	return nil
+}
+
+// sameSchedule reports whether two reminders are set for the same moment
+// relative to the task
+func (r *Reminder) sameSchedule(other *Reminder) bool {
+	if r.At != nil || other.At != nil {
+		return r.At != nil && other.At != nil && r.At.Equal(*other.At)
+	}
+	return r.OffsetMinutes == other.OffsetMinutes
+}
+
+// ArmReminders works out when each reminder is due. A reminder that was
+// already sent stays sent unless the due date moved, which re-arms every one.
+// It builds a new slice, so tasks copied from one another don't share state.
+func (t *Task) ArmReminders(previous []Reminder, dueDateChanged bool) {
+	if t.Reminders == nil {
+		return
+	}
+	armed := make([]Reminder, len(t.Reminders))
+	for i, reminder := range t.Reminders {
+		reminder.SentAt = nil
+		if reminder.At != nil {
+			reminder.RemindAt = *reminder.At
+		} else {
+			reminder.RemindAt = t.DueDate.Add(-time.Duration(reminder.OffsetMinutes) * time.Minute)
+		}
+		if !dueDateChanged {
+			for _, old := range previous {
+				if old.SentAt != nil && reminder.sameSchedule(&old) {
+					reminder.SentAt = old.SentAt
+					break
+				}
+			}
+		}
+		armed[i] = reminder
+	}
+	t.Reminders = armed
+}
+
+func validateReminders(reminders []Reminder) error {
+	if len(reminders) > MaxTaskReminders {
+		return fmt.Errorf("a task can have at most %d reminders", MaxTaskReminders)
+	}
+	for i := range reminders {
+		if err := reminders[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
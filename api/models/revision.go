@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RevisionBaseline = "baseline"
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionRestored = "restored"
+)
+
+// TaskRevision is a full snapshot of a task as it was saved. Revisions are
+// numbered from 1 per task; a baseline revision captures tasks created before
+// revisions were recorded, the first time they are changed.
+type TaskRevision struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	TaskID       string             `json:"task_id" bson:"task_id"`
+	Number       int                `json:"number" bson:"number"`
+	Action       string             `json:"action" bson:"action"`
+	AuthorID     string             `json:"author_id" bson:"author_id"`
+	RestoredFrom int                `json:"restored_from,omitempty" bson:"restored_from,omitempty"`
+	Snapshot     Task               `json:"snapshot" bson:"snapshot"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// RevisionDiff lists the fields that differ between two revisions of a task
+type RevisionDiff struct {
+	TaskID  string        `json:"task_id"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
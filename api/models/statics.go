@@ -10,12 +10,15 @@ import (
 type TaskStatistics struct {
 	ID             primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
 	UserID         string               `json:"user_id" bson:"user_id"`
+	ProjectID      string               `json:"project_id,omitempty" bson:"project_id,omitempty"`
 	TotalTasks     int                  `json:"total_tasks"`
 	CompletedTasks int                  `json:"completed_tasks"`
 	PendingTasks   int                  `json:"pending_tasks"`
 	OverdueTasks   int                  `json:"overdue_tasks"`
 	CompletionRate float64              `json:"completion_rate"`
+	ByStatus       map[string]int       `json:"by_status"`
 	ByPriority     map[string]int       `json:"by_priority"`
+	ByProject      map[string]int       `json:"by_project"`
 	UpdatedAt      time.Time            `json:"updated_at"`
 }
 
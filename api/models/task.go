@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -15,53 +16,116 @@ type Task struct {
     DueDate          time.Time          `json:"due_date" bson:"due_date"`
     Priority         string             `json:"priority" bson:"priority"`
     Status           string             `json:"status" bson:"status"`
+    // Rank orders the task within its board column
+    Rank             string             `json:"rank,omitempty" bson:"rank,omitempty"`
     UserID           string             `json:"user_id" bson:"user_id"`
-    Collaborators    []string           `json:"collaborators" bson:"collaborators"`
+    ParentID         string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+    ProjectID        string             `json:"project_id,omitempty" bson:"project_id,omitempty"`
+    Collaborators    []Collaborator     `json:"collaborators" bson:"collaborators"`
     Tags             []string           `json:"tags" bson:"tags"`
+    BlockedBy        []string           `json:"blocked_by,omitempty" bson:"blocked_by,omitempty"`
+    Blocks           []string           `json:"blocks,omitempty" bson:"blocks,omitempty"`
     CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
     UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+    // Version goes up with every change and backs the task's ETag
+    Version          int64              `json:"version" bson:"version"`
     CompletedAt      *time.Time         `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
-    HourReminderSent bool               `json:"hour_reminder_sent" bson:"hour_reminder_sent"`
+    ArchivedAt       *time.Time         `json:"archived_at,omitempty" bson:"archived_at,omitempty"`
+    // UnarchivedAt holds off auto-archiving until the task has been back for the full period
+    UnarchivedAt     *time.Time         `json:"unarchived_at,omitempty" bson:"unarchived_at,omitempty"`
+    Reminders        []Reminder         `json:"reminders" bson:"reminders"`
+    Recurrence       *RecurrenceRule    `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+    RecurrenceOf     string             `json:"recurrence_of,omitempty" bson:"recurrence_of,omitempty"`
+    DeletedAt        *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+    DeletedBy        string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+    // TrashedWith is the task whose deletion moved this subtask to the trash
+    TrashedWith      string             `json:"trashed_with,omitempty" bson:"trashed_with,omitempty"`
 }
 
 
-func (t *Task) Validate() error {
-	// Title
-	t.Title = strings.TrimSpace(t.Title)
-	if len(t.Title) < 3 || len(t.Title) > 100 {
-		return errors.New("title must be between 3 and 100 characters")
-	}
+// EditableTaskFields are the fields a client sets on a task; everything else
+// is derived or managed through its own endpoints
+var EditableTaskFields = []string{
+	"title", "description", "due_date", "priority", "status", "tags", "recurrence", "project_id",
+	"reminders",
+}
 
-	// Description
-	t.Description = strings.TrimSpace(t.Description)
-	if len(t.Description) > 1000 {
-		return errors.New("description cannot exceed 1000 characters")
+// Validate checks the task against the workflow that governs it; nil means
+// the default workflow
+func (t *Task) Validate(workflow *Workflow) error {
+	if err := t.ValidateFields(workflow, EditableTaskFields...); err != nil {
+		return err
 	}
 
-	// Due Date
-	if t.DueDate.Before(time.Now()) {
-		return errors.New("due date cannot be in the past")
+	// UserID
+	if t.UserID == "" {
+		return errors.New("user ID is required")
 	}
+	return nil
+}
 
-	// Priority
-	t.Priority = strings.TrimSpace(t.Priority)
-	switch t.Priority {
-	case "High", "Medium", "Low", "Urgent":
-	default:
-		return errors.New("priority must be High, Medium, Low, or Urgent")
-	}
+// ValidateFields checks only the named fields, so a partial update isn't
+// rejected over a field it leaves alone, such as a due date now in the past
+func (t *Task) ValidateFields(workflow *Workflow, fields ...string) error {
+	for _, field := range fields {
+		switch field {
+		case "title":
+			t.Title = strings.TrimSpace(t.Title)
+			if len(t.Title) < 3 || len(t.Title) > 100 {
+				return errors.New("title must be between 3 and 100 characters")
+			}
 
-	// Status
-	t.Status = strings.TrimSpace(t.Status)
-	switch t.Status {
-	case "Pending", "In Progress", "Completed":
-	default:
-		return errors.New("status must be Pending, In Progress, or Completed")
-	}
+		case "description":
+			t.Description = strings.TrimSpace(t.Description)
+			if len(t.Description) > 1000 {
+				return errors.New("description cannot exceed 1000 characters")
+			}
 
-	// UserID
-	if t.UserID == "" {
-		return errors.New("user ID is required")
+		case "due_date":
+			if t.DueDate.Before(time.Now()) {
+				return errors.New("due date cannot be in the past")
+			}
+
+		case "priority":
+			t.Priority = strings.TrimSpace(t.Priority)
+			switch t.Priority {
+			case "High", "Medium", "Low", "Urgent":
+			default:
+				return errors.New("priority must be High, Medium, Low, or Urgent")
+			}
+
+		case "status":
+			if workflow == nil {
+				workflow = DefaultWorkflow()
+			}
+			t.Status = strings.TrimSpace(t.Status)
+			if !workflow.HasStatus(t.Status) {
+				return fmt.Errorf("status must be one of: %s", strings.Join(workflow.StatusNames(), ", "))
+			}
+
+		case "reminders":
+			if err := validateReminders(t.Reminders); err != nil {
+				return err
+			}
+
+		case "recurrence":
+			if t.Recurrence != nil {
+				if err := t.Recurrence.Validate(); err != nil {
+					return err
+				}
+			}
+		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// TaskDependency is an edge in a dependency graph: From blocks To
+type TaskDependency struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type DependencyGraph struct {
+	Nodes []Task           `json:"nodes"`
+	Edges []TaskDependency `json:"edges"`
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits on what a single template can create
+const (
+	MaxTemplateSubtasks  = 50
+	MaxTemplateChecklist = 50
+)
+
+// placeholderPattern matches {{name}} placeholders in template text
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// TemplateTask describes a task a template creates. The due date is relative
+// to the day the template is instantiated.
+type TemplateTask struct {
+	Title          string   `json:"title" bson:"title"`
+	Description    string   `json:"description" bson:"description"`
+	Priority       string   `json:"priority" bson:"priority"`
+	Tags           []string `json:"tags" bson:"tags"`
+	DueOffsetDays  int      `json:"due_offset_days" bson:"due_offset_days"`
+	DueOffsetHours int      `json:"due_offset_hours" bson:"due_offset_hours"`
+}
+
+type TaskTemplate struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	OwnerID      string             `json:"owner_id" bson:"owner_id"`
+	Name         string             `json:"name" bson:"name"`
+	TemplateTask `bson:",inline"`
+	// Checklist items are written into the task description as a checklist
+	Checklist []string       `json:"checklist" bson:"checklist"`
+	Subtasks  []TemplateTask `json:"subtasks" bson:"subtasks"`
+	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// InstantiateTemplateRequest creates tasks from a template. Variables fill the
+// template's placeholders; Start is what due offsets count from, now by default.
+type InstantiateTemplateRequest struct {
+	ProjectID string            `json:"project_id"`
+	Variables map[string]string `json:"variables"`
+	Start     *time.Time        `json:"start"`
+}
+
+// validate applies the task rules for title, description and priority to the
+// template text, so a template can't be saved that could never instantiate
+func (t *TemplateTask) validate() error {
+	if strings.TrimSpace(t.Priority) == "" {
+		t.Priority = "Medium"
+	}
+	task := Task{Title: t.Title, Description: t.Description, Priority: t.Priority}
+	if err := task.ValidateFields(nil, "title", "description", "priority"); err != nil {
+		return err
+	}
+	t.Title, t.Description, t.Priority = task.Title, task.Description, task.Priority
+
+	if t.DueOffsetDays < 0 || t.DueOffsetHours < 0 {
+		return errors.New("due offsets cannot be negative")
+	}
+	if t.DueOffsetDays == 0 && t.DueOffsetHours == 0 {
+		return errors.New("due offset must be at least an hour")
+	}
+	return nil
+}
+
+// Validate checks the template's shape. The tasks it creates are validated
+// again once their placeholders are filled in at instantiation.
+func (t *TaskTemplate) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if len(t.Name) < 3 || len(t.Name) > 100 {
+		return errors.New("template name must be between 3 and 100 characters")
+	}
+	if err := t.TemplateTask.validate(); err != nil {
+		return err
+	}
+
+	if len(t.Checklist) > MaxTemplateChecklist {
+		return fmt.Errorf("a template can have at most %d checklist items", MaxTemplateChecklist)
+	}
+	for i, item := range t.Checklist {
+		t.Checklist[i] = strings.TrimSpace(item)
+		if t.Checklist[i] == "" {
+			return errors.New("checklist items cannot be empty")
+		}
+	}
+
+	if len(t.Subtasks) > MaxTemplateSubtasks {
+		return fmt.Errorf("a template can have at most %d subtasks", MaxTemplateSubtasks)
+	}
+	for i := range t.Subtasks {
+		if err := t.Subtasks[i].validate(); err != nil {
+			return fmt.Errorf("subtask %d: %w", i+1, err)
+		}
+	}
+
+	if t.OwnerID == "" {
+		return errors.New("owner ID is required")
+	}
+	return nil
+}
+
+// DueDate returns the task's due date when instantiated at start
+func (t *TemplateTask) DueDate(start time.Time) time.Time {
+	return start.AddDate(0, 0, t.DueOffsetDays).Add(time.Duration(t.DueOffsetHours) * time.Hour)
+}
+
+// ChecklistDescription appends the checklist to a description as task-list items
+func ChecklistDescription(description string, checklist []string) string {
+	if len(checklist) == 0 {
+		return description
+	}
+	var b strings.Builder
+	b.WriteString(description)
+	if description != "" {
+		b.WriteString("\n\n")
+	}
+	for i, item := range checklist {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("- [ ] " + item)
+	}
+	return b.String()
+}
+
+// ExpandPlaceholders replaces every {{name}} in text with its variable. It
+// fails listing the names that have no value rather than leave them in place.
+func ExpandPlaceholders(text string, variables map[string]string) (string, error) {
+	missing := map[string]bool{}
+	expanded := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := variables[name]
+		if !ok {
+			missing[name] = true
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("missing values for placeholders: %s", strings.Join(names, ", "))
+	}
+	return expanded, nil
+}
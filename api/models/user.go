@@ -10,12 +10,95 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Events users can turn email notifications off for one at a time
+const (
+	NotifyReminders         = "reminders"
+	NotifyComments          = "comments"
+	NotifyMentions          = "mentions"
+	NotifyCollaboratorAdded = "collaborator_added"
+	NotifyStatusChanges     = "status_changes"
+	NotifyDigest            = "digest"
+)
+
+// NotificationSettings holds a toggle per event; the bson names match the events
+type NotificationSettings struct {
+	Reminders         bool `json:"reminders" bson:"reminders"`
+	Comments          bool `json:"comments" bson:"comments"`
+	Mentions          bool `json:"mentions" bson:"mentions"`
+	CollaboratorAdded bool `json:"collaborator_added" bson:"collaborator_added"`
+	StatusChanges     bool `json:"status_changes" bson:"status_changes"`
+	Digest            bool `json:"digest" bson:"digest"`
+}
+
+// DefaultNotificationSettings turns every event on
+func DefaultNotificationSettings() NotificationSettings {
+	return NotificationSettings{
+		Reminders:         true,
+		Comments:          true,
+		Mentions:          true,
+		CollaboratorAdded: true,
+		StatusChanges:     true,
+		Digest:            true,
+	}
+}
+
+// IsNotificationEvent reports whether event is one users can turn off
+func IsNotificationEvent(event string) bool {
+	switch event {
+	case NotifyReminders, NotifyComments, NotifyMentions, NotifyCollaboratorAdded, NotifyStatusChanges, NotifyDigest:
+		return true
+	}
+	return false
+}
+
+func (s NotificationSettings) Enabled(event string) bool {
+	switch event {
+	case NotifyReminders:
+		return s.Reminders
+	case NotifyComments:
+		return s.Comments
+	case NotifyMentions:
+		return s.Mentions
+	case NotifyCollaboratorAdded:
+		return s.CollaboratorAdded
+	case NotifyStatusChanges:
+		return s.StatusChanges
+	case NotifyDigest:
+		return s.Digest
+	}
+	return false
+}
+
 type UserPreferences struct {
-	Timezone           string `json:"timezone" bson:"timezone"`
-	EmailNotifications bool   `json:"email_notifications" bson:"email_notifications"`
-	PushNotifications  bool   `json:"push_notifications" bson:"push_notifications"`
-	DailyDigest        bool   `json:"daily_digest" bson:"daily_digest"`
+	Timezone           string               `json:"timezone" bson:"timezone"`
+	EmailNotifications bool                 `json:"email_notifications" bson:"email_notifications"`
+	PushNotifications  bool                 `json:"push_notifications" bson:"push_notifications"`
+	DailyDigest        bool                 `json:"daily_digest" bson:"daily_digest"`
+	Notifications      NotificationSettings `json:"notifications" bson:"notifications"`
+}
+
+// WantsEmail reports whether the user wants email about an event: email
+// notifications have to be on as a whole and for that event
+func (p UserPreferences) WantsEmail(event string) bool {
+	return p.EmailNotifications && p.Notifications.Enabled(event)
+}
+// Location returns the user's preferred timezone, or UTC if none is set or it
+// can't be loaded
+func (p UserPreferences) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
 }
+
+// EditableUserFields are the fields users change through PATCH /api/users/me;
+// the email, its verification, the password and preferences are managed elsewhere
+var EditableUserFields = []string{"name"}
+
 type User struct {
 	ID                primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Name              string             `json:"name,omitempty" bson:"name,omitempty"`
@@ -25,6 +108,8 @@ type User struct {
 	Preferences       UserPreferences    `json:"preferences" bson:"preferences"`
 	EmailVerified     bool               `json:"email_verified" bson:"email_verified"`
 	VerificationToken string             `json:"verification_token,omitempty" bson:"verification_token,omitempty"`
+	// LastDigestDate is the local date, as 2006-01-02, of the last daily digest
+	LastDigestDate    string             `json:"-" bson:"last_digest_date,omitempty"`
 	CreatedAt         time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt         time.Time          `json:"updated_at" bson:"updated_at"`
 }
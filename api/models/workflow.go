@@ -0,0 +1,176 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorkflowStatus is one step of a workflow. Done statuses complete a task;
+// active statuses mean work has started, which a blocked task cannot do.
+type WorkflowStatus struct {
+	Name   string `json:"name" bson:"name"`
+	Done   bool   `json:"done" bson:"done"`
+	Active bool   `json:"active" bson:"active"`
+}
+
+type WorkflowTransition struct {
+	From string `json:"from" bson:"from"`
+	To   string `json:"to" bson:"to"`
+}
+
+// Workflow is the ordered set of statuses a task can be in. It belongs either
+// to a user, for their personal tasks, or to a project. Without transitions a
+// task can move between any two statuses.
+type Workflow struct {
+	ID          primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
+	OwnerID     string               `json:"owner_id,omitempty" bson:"owner_id,omitempty"`
+	ProjectID   string               `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	Statuses    []WorkflowStatus     `json:"statuses" bson:"statuses"`
+	Transitions []WorkflowTransition `json:"transitions" bson:"transitions"`
+	CreatedAt   time.Time            `json:"created_at,omitempty" bson:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at,omitempty" bson:"updated_at"`
+}
+
+type WorkflowRequest struct {
+	Statuses    []WorkflowStatus     `json:"statuses"`
+	Transitions []WorkflowTransition `json:"transitions"`
+}
+
+// DefaultWorkflow is used wherever no workflow has been configured
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Statuses: []WorkflowStatus{
+			{Name: "Pending"},
+			{Name: "In Progress", Active: true},
+			{Name: "Completed", Done: true},
+		},
+		Transitions: []WorkflowTransition{},
+	}
+}
+
+func (wf *Workflow) Validate() error {
+	if len(wf.Statuses) < 2 || len(wf.Statuses) > 20 {
+		return errors.New("a workflow must have between 2 and 20 statuses")
+	}
+
+	seen := make(map[string]bool, len(wf.Statuses))
+	var hasDone, hasOpen bool
+	for i := range wf.Statuses {
+		status := &wf.Statuses[i]
+		status.Name = strings.TrimSpace(status.Name)
+		if len(status.Name) < 1 || len(status.Name) > 30 {
+			return errors.New("status names must be between 1 and 30 characters")
+		}
+		if seen[status.Name] {
+			return fmt.Errorf("status %q is listed more than once", status.Name)
+		}
+		seen[status.Name] = true
+
+		if status.Done {
+			hasDone = true
+		} else {
+			hasOpen = true
+		}
+	}
+	if !hasDone || !hasOpen {
+		return errors.New("a workflow needs at least one done and one not-done status")
+	}
+	if wf.Statuses[0].Done {
+		return errors.New("the first status is where new tasks start and cannot be done")
+	}
+
+	for i := range wf.Transitions {
+		transition := &wf.Transitions[i]
+		transition.From = strings.TrimSpace(transition.From)
+		transition.To = strings.TrimSpace(transition.To)
+		if !seen[transition.From] || !seen[transition.To] {
+			return fmt.Errorf("transition %q -> %q uses an unknown status", transition.From, transition.To)
+		}
+	}
+	if wf.Transitions == nil {
+		wf.Transitions = []WorkflowTransition{}
+	}
+	return nil
+}
+
+func (wf *Workflow) Status(name string) (WorkflowStatus, bool) {
+	for _, status := range wf.Statuses {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+func (wf *Workflow) HasStatus(name string) bool {
+	_, ok := wf.Status(name)
+	return ok
+}
+
+func (wf *Workflow) IsDone(name string) bool {
+	status, ok := wf.Status(name)
+	return ok && status.Done
+}
+
+func (wf *Workflow) IsActive(name string) bool {
+	status, ok := wf.Status(name)
+	return ok && status.Active
+}
+
+func (wf *Workflow) StatusNames() []string {
+	names := make([]string, 0, len(wf.Statuses))
+	for _, status := range wf.Statuses {
+		names = append(names, status.Name)
+	}
+	return names
+}
+
+func (wf *Workflow) DoneStatuses() []string {
+	names := make([]string, 0)
+	for _, status := range wf.Statuses {
+		if status.Done {
+			names = append(names, status.Name)
+		}
+	}
+	return names
+}
+
+// InitialStatus is the status new tasks start in
+func (wf *Workflow) InitialStatus() string {
+	return wf.Statuses[0].Name
+}
+
+// CompletedStatus is the status a parent moves to once all its subtasks are done
+func (wf *Workflow) CompletedStatus() string {
+	return wf.DoneStatuses()[0]
+}
+
+// ReopenedStatus is the status a done parent falls back to when it gets open
+// subtasks again: the first active status, or the initial one
+func (wf *Workflow) ReopenedStatus() string {
+	for _, status := range wf.Statuses {
+		if status.Active && !status.Done {
+			return status.Name
+		}
+	}
+	return wf.InitialStatus()
+}
+
+// CanTransition reports whether a task may move from one status to another.
+// Tasks in a status the workflow doesn't know, e.g. after the task moved to
+// another project, may move anywhere.
+func (wf *Workflow) CanTransition(from, to string) bool {
+	if from == to || len(wf.Transitions) == 0 || !wf.HasStatus(from) {
+		return true
+	}
+	for _, transition := range wf.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"api/configs"
+	"api/models"
+	"api/repositories"
+	"context"
+	"time"
+)
+
+// Inbox stores in-app notifications. It is the only notification repository;
+// the notification endpoints are built on it too.
+var Inbox = repositories.NewNotificationRepository(configs.GetCollection(configs.DB, "notifications"))
+
+// Record puts a copy of the notification in the in-app inbox of each user
+func Record(ctx context.Context, userIDs []string, notification models.Notification) error {
+	now := time.Now()
+	notifications := make([]models.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notification.UserID = userID
+		notification.CreatedAt = now
+		notifications = append(notifications, notification)
+	}
+	return Inbox.CreateMany(ctx, notifications)
+}
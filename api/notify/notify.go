@@ -0,0 +1,80 @@
+// Package notify tells users about task events, in their in-app inbox and by
+// email. Every email about a task event goes through here, so the recipient's
+// preferences are always honoured and each email carries a one-click
+// unsubscribe link. Transactional emails, such as address verification and
+// invitations, are sent directly.
+package notify
+
+import (
+	"api/configs"
+	"api/models"
+	"api/utils"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const unsubscribeLinkPurpose = "unsubscribe"
+
+// unsubscribeTTL is long because digests and reminders sit in inboxes for a while
+const unsubscribeTTL = 365 * 24 * time.Hour
+
+var userCollection = configs.GetCollection(configs.DB, "users")
+
+// UnsubscribeToken signs a link that turns off one event for one user
+func UnsubscribeToken(userID, event string) (string, error) {
+	return utils.SignLinkToken(unsubscribeLinkPurpose, userID+":"+event, unsubscribeTTL)
+}
+
+// ParseUnsubscribeToken returns the user and event an unsubscribe link was made for
+func ParseUnsubscribeToken(token string) (string, string, error) {
+	subject, err := utils.ParseLinkToken(unsubscribeLinkPurpose, token)
+	if err != nil {
+		return "", "", err
+	}
+	userID, event, ok := strings.Cut(subject, ":")
+	if !ok || !models.IsNotificationEvent(event) {
+		return "", "", fmt.Errorf("invalid or expired link")
+	}
+	return userID, event, nil
+}
+
+// Email sends an email about an event unless the user turned it off. Skipping
+// it is not an error.
+func Email(user *models.User, event, subject, htmlBody string) error {
+	if !user.Preferences.WantsEmail(event) {
+		return nil
+	}
+
+	token, err := UnsubscribeToken(user.ID.Hex(), event)
+	if err != nil {
+		return err
+	}
+	unsubscribeURL := fmt.Sprintf("%s/api/users/notifications/unsubscribe?token=%s", os.Getenv("APP_URL"), token)
+
+	htmlBody += fmt.Sprintf(`
+		<p style="font-size:12px;color:#888">Don't want these emails? <a href="%s">Unsubscribe</a>.</p>
+	`, unsubscribeURL)
+	return utils.SendEmailWithHeaders(user.Email, subject, htmlBody, map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	})
+}
+
+// EmailUser loads the user and sends them an email about an event
+func EmailUser(ctx context.Context, userID, event, subject, htmlBody string) error {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+	var user models.User
+	if err = userCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+		return err
+	}
+	return Email(&user, event, subject, htmlBody)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ActivityRepository struct {
+	collection *mongo.Collection
+}
+
+func NewActivityRepository(collection *mongo.Collection) *ActivityRepository {
+	// Before/after values are stored untyped; decode nested documents as maps
+	// so they serialize to plain JSON objects.
+	collection, _ = collection.Clone(options.Collection().SetBSONOptions(&options.BSONOptions{
+		DefaultDocumentM: true,
+	}))
+	return &ActivityRepository{
+		collection: collection,
+	}
+}
+
+func (r *ActivityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	_, err := r.collection.InsertOne(ctx, activity)
+	return err
+}
+
+// FindPage returns one page of activity matching the filter, newest first, and the total count
+func (r *ActivityRepository) FindPage(ctx context.Context, filter bson.M, page, limit int64) ([]models.Activity, int64, error) {
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page-1)*limit).
+		SetLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	activities := make([]models.Activity, 0)
+	if err = cursor.All(ctx, &activities); err != nil {
+		return nil, 0, err
+	}
+	return activities, total, nil
+}
+
+// RedactComment drops the recorded content changes of a comment, so its text
+// doesn't outlive the comment in the activity log
+func (r *ActivityRepository) RedactComment(ctx context.Context, commentID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"comment_id": commentID},
+		bson.M{"$unset": bson.M{"changes": ""}},
+	)
+	return err
+}
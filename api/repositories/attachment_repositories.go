@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AttachmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAttachmentRepository(collection *mongo.Collection) *AttachmentRepository {
+	return &AttachmentRepository{
+		collection: collection,
+	}
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	_, err := r.collection.InsertOne(ctx, attachment)
+	return err
+}
+
+func (r *AttachmentRepository) FindByID(ctx context.Context, taskID string, id primitive.ObjectID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "task_id": taskID}).Decode(&attachment)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) FindByTasks(ctx context.Context, taskIDs []string) ([]models.Attachment, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"task_id": bson.M{"$in": taskIDs}},
+		options.Find().SetSort(bson.M{"created_at": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]models.Attachment, 0)
+	if err = cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *AttachmentRepository) DeleteByIDs(ctx context.Context, ids []primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
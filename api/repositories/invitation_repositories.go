@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InvitationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInvitationRepository(collection *mongo.Collection) *InvitationRepository {
+	return &InvitationRepository{
+		collection: collection,
+	}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, invitation *models.Invitation) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, invitation)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *InvitationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) FindPending(ctx context.Context, taskID string, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.collection.FindOne(ctx, bson.M{
+		"task_id": taskID,
+		"email":   email,
+		"status":  models.InvitationPending,
+	}).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) find(ctx context.Context, filter bson.M) ([]models.Invitation, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]models.Invitation, 0)
+	if err = cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *InvitationRepository) FindByTask(ctx context.Context, taskID string) ([]models.Invitation, error) {
+	return r.find(ctx, bson.M{"task_id": taskID})
+}
+
+func (r *InvitationRepository) FindPendingByInvitee(ctx context.Context, userID string) ([]models.Invitation, error) {
+	return r.find(ctx, bson.M{
+		"invitee_id": userID,
+		"status":     models.InvitationPending,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+}
+
+func (r *InvitationRepository) UpdateInvitation(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": update},
+	)
+	return err
+}
+
+// ClaimByEmail links every pending invitation for an address to the account that now owns it
+func (r *InvitationRepository) ClaimByEmail(ctx context.Context, email string, userID string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"email": email, "status": models.InvitationPending},
+		bson.M{"$set": bson.M{"invitee_id": userID, "updated_at": time.Now()}},
+	)
+	return err
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationRepository(collection *mongo.Collection) *NotificationRepository {
+	return &NotificationRepository{
+		collection: collection,
+	}
+}
+
+func (r *NotificationRepository) CreateMany(ctx context.Context, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	documents := make([]any, 0, len(notifications))
+	for _, notification := range notifications {
+		documents = append(documents, notification)
+	}
+	_, err := r.collection.InsertMany(ctx, documents)
+	return err
+}
+
+func unreadFilter(userID string) bson.M {
+	return bson.M{"user_id": userID, "read_at": bson.M{"$exists": false}}
+}
+
+// FindPage returns one page of the user's notifications, newest first, and the total count
+func (r *NotificationRepository) FindPage(ctx context.Context, userID string, unreadOnly bool, page, limit int64) ([]models.Notification, int64, error) {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter = unreadFilter(userID)
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page-1)*limit).
+		SetLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notifications := make([]models.Notification, 0)
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	return r.collection.CountDocuments(ctx, unreadFilter(userID))
+}
+
+func (r *NotificationRepository) FindByID(ctx context.Context, id primitive.ObjectID, userID string) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(&notification)
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkRead marks one of the user's notifications as read; reading it again keeps the first time
+func (r *NotificationRepository) MarkRead(ctx context.Context, id primitive.ObjectID, userID string, readAt time.Time) error {
+	filter := unreadFilter(userID)
+	filter["_id"] = id
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"read_at": readAt}})
+	return err
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many there were
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, readAt time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, unreadFilter(userID), bson.M{"$set": bson.M{"read_at": readAt}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
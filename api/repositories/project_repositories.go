@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProjectRepository struct {
+	collection *mongo.Collection
+}
+
+func NewProjectRepository(collection *mongo.Collection) *ProjectRepository {
+	return &ProjectRepository{
+		collection: collection,
+	}
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project *models.Project) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, project)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *ProjectRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Project, error) {
+	var project models.Project
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&project)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) FindByMember(ctx context.Context, userID string) ([]models.Project, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"members.user_id": userID},
+		options.Find().SetSort(bson.M{"created_at": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]models.Project, 0)
+	if err = cursor.All(ctx, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// FindIDsByMember returns the hex IDs of every project the user belongs to
+func (r *ProjectRepository) FindIDsByMember(ctx context.Context, userID string) ([]string, error) {
+	projects, err := r.FindByMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, project.ID.Hex())
+	}
+	return ids, nil
+}
+
+func (r *ProjectRepository) IsMember(ctx context.Context, projectID string, userID string) (bool, error) {
+	id, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return false, nil
+	}
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id, "members.user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *ProjectRepository) UpdateProject(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": update},
+	)
+	return err
+}
+
+func (r *ProjectRepository) AddMember(ctx context.Context, id primitive.ObjectID, member models.ProjectMember) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "members.user_id": bson.M{"$ne": member.UserID}},
+		bson.M{
+			"$push": bson.M{"members": member},
+			"$set":  bson.M{"updated_at": member.AddedAt},
+		},
+	)
+	return err
+}
+
+func (r *ProjectRepository) RemoveMember(ctx context.Context, id primitive.ObjectID, userID string) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID}}},
+	)
+	return err
+}
+
+func (r *ProjectRepository) DeleteProject(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevisionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRevisionRepository(collection *mongo.Collection) *RevisionRepository {
+	return &RevisionRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes makes revision numbers unique per task, which saving a
+// revision relies on to detect a concurrent save
+func (r *RevisionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *RevisionRepository) Create(ctx context.Context, revision *models.TaskRevision) error {
+	_, err := r.collection.InsertOne(ctx, revision)
+	return err
+}
+
+// LatestNumber returns the number of the newest revision of a task, or 0 if it has none
+func (r *RevisionRepository) LatestNumber(ctx context.Context, taskID string) (int, error) {
+	var revision models.TaskRevision
+	err := r.collection.FindOne(ctx,
+		bson.M{"task_id": taskID},
+		options.FindOne().SetSort(bson.M{"number": -1}).SetProjection(bson.M{"number": 1}),
+	).Decode(&revision)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	return revision.Number, err
+}
+
+func (r *RevisionRepository) FindByTask(ctx context.Context, taskID string) ([]models.TaskRevision, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"task_id": taskID},
+		options.Find().SetSort(bson.M{"number": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]models.TaskRevision, 0)
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepository) FindByNumber(ctx context.Context, taskID string, number int) (*models.TaskRevision, error) {
+	var revision models.TaskRevision
+	err := r.collection.FindOne(ctx, bson.M{"task_id": taskID, "number": number}).Decode(&revision)
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *RevisionRepository) DeleteByTasks(ctx context.Context, taskIDs []string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	return err
+}
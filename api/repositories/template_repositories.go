@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TemplateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTemplateRepository(collection *mongo.Collection) *TemplateRepository {
+	return &TemplateRepository{
+		collection: collection,
+	}
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, template *models.TaskTemplate) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, template)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindByID returns the owner's template, or mongo.ErrNoDocuments
+func (r *TemplateRepository) FindByID(ctx context.Context, id primitive.ObjectID, ownerID string) (*models.TaskTemplate, error) {
+	var template models.TaskTemplate
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "owner_id": ownerID}).Decode(&template)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *TemplateRepository) FindByOwner(ctx context.Context, ownerID string) ([]models.TaskTemplate, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"owner_id": ownerID},
+		options.Find().SetSort(bson.M{"name": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []models.TaskTemplate{}
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *TemplateRepository) Replace(ctx context.Context, template *models.TaskTemplate) error {
+	_, err := r.collection.ReplaceOne(ctx,
+		bson.M{"_id": template.ID, "owner_id": template.OwnerID},
+		template,
+	)
+	return err
+}
+
+// Delete removes the owner's template and reports whether there was one
+func (r *TemplateRepository) Delete(ctx context.Context, id primitive.ObjectID, ownerID string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "owner_id": ownerID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
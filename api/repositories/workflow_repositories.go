@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"api/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WorkflowRepository struct {
+	collection *mongo.Collection
+}
+
+func NewWorkflowRepository(collection *mongo.Collection) *WorkflowRepository {
+	return &WorkflowRepository{
+		collection: collection,
+	}
+}
+
+// scopeFilter matches the workflow of a project, or a user's personal workflow
+func scopeFilter(ownerID, projectID string) bson.M {
+	if projectID != "" {
+		return bson.M{"project_id": projectID}
+	}
+	return bson.M{"owner_id": ownerID, "project_id": bson.M{"$exists": false}}
+}
+
+// FindByScope returns the configured workflow, or mongo.ErrNoDocuments if there is none
+func (r *WorkflowRepository) FindByScope(ctx context.Context, ownerID, projectID string) (*models.Workflow, error) {
+	var workflow models.Workflow
+	err := r.collection.FindOne(ctx, scopeFilter(ownerID, projectID)).Decode(&workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// Save creates or replaces the workflow of a user or project
+func (r *WorkflowRepository) Save(ctx context.Context, workflow *models.Workflow) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		scopeFilter(workflow.OwnerID, workflow.ProjectID),
+		bson.M{
+			"$set": bson.M{
+				"statuses":    workflow.Statuses,
+				"transitions": workflow.Transitions,
+				"updated_at":  now,
+			},
+			// The owner or project ID comes from the filter on insert
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"api/controllers"
+	"api/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterInvitationRoutes(r *mux.Router, invitationController *controllers.InvitationController) {
+	// Task owner routes
+	r.HandleFunc("/api/tasks/{id}/invitations", middleware.AuthMiddleware(
+		invitationController.InviteCollaborator)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/invitations", middleware.AuthMiddleware(
+		invitationController.GetTaskInvitations)).Methods("GET")
+
+	// Invitee routes
+	r.HandleFunc("/api/invitations", middleware.AuthMiddleware(
+		invitationController.GetMyInvitations)).Methods("GET")
+	r.HandleFunc("/api/invitations/{id}/accept", middleware.AuthMiddleware(
+		invitationController.AcceptInvitation)).Methods("POST")
+	r.HandleFunc("/api/invitations/{id}/decline", middleware.AuthMiddleware(
+		invitationController.DeclineInvitation)).Methods("POST")
+
+	// Signed links from the invitation email
+	r.HandleFunc("/api/invitations/respond", invitationController.ConfirmInvitation).
+		Methods("GET")
+	r.HandleFunc("/api/invitations/respond", invitationController.RespondToInvitation).
+		Methods("POST")
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"api/controllers"
+	"api/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterNotificationRoutes(r *mux.Router, notificationController *controllers.NotificationController) {
+	r.HandleFunc("/api/notifications", middleware.AuthMiddleware(
+		notificationController.GetNotifications)).Methods("GET")
+	r.HandleFunc("/api/notifications/unread-count", middleware.AuthMiddleware(
+		notificationController.GetUnreadCount)).Methods("GET")
+	r.HandleFunc("/api/notifications/read-all", middleware.AuthMiddleware(
+		notificationController.MarkAllRead)).Methods("POST")
+	r.HandleFunc("/api/notifications/{id}/read", middleware.AuthMiddleware(
+		notificationController.MarkRead)).Methods("POST")
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"api/controllers"
+	"api/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterProjectRoutes(r *mux.Router, projectController *controllers.ProjectController) {
+	// Project management routes
+	r.HandleFunc("/api/projects", middleware.AuthMiddleware(projectController.CreateProject)).
+		Methods("POST")
+	r.HandleFunc("/api/projects", middleware.AuthMiddleware(projectController.GetProjects)).
+		Methods("GET")
+	r.HandleFunc("/api/projects/{id}", middleware.AuthMiddleware(projectController.GetProject)).
+		Methods("GET")
+	r.HandleFunc("/api/projects/{id}", middleware.AuthMiddleware(projectController.UpdateProject)).
+		Methods("PUT")
+	r.HandleFunc("/api/projects/{id}", middleware.AuthMiddleware(projectController.DeleteProject)).
+		Methods("DELETE")
+
+	// Project membership routes
+	r.HandleFunc("/api/projects/{id}/members", middleware.AuthMiddleware(
+		projectController.AddMember)).Methods("POST")
+	r.HandleFunc("/api/projects/{id}/members/{userId}", middleware.AuthMiddleware(
+		projectController.RemoveMember)).Methods("DELETE")
+}
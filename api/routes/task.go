@@ -14,6 +14,26 @@ func RegisterTaskRoutes(r *mux.Router) {
 		controllers.GetTaskStatistics)).Methods("GET")
 
 
+	// Activity feed across all of the user's tasks
+	r.HandleFunc("/api/activity", middleware.AuthMiddleware(
+		controllers.GetActivityFeed)).Methods("GET")
+
+	// Bulk operations
+	r.HandleFunc("/api/tasks/bulk", middleware.AuthMiddleware(
+		controllers.BulkUpdateTasks)).Methods("POST")
+
+	// Board routes
+	r.HandleFunc("/api/tasks/board", middleware.AuthMiddleware(
+		controllers.GetBoard)).Methods("GET")
+
+	// Trash routes
+	r.HandleFunc("/api/tasks/trash", middleware.AuthMiddleware(
+		controllers.GetTrash)).Methods("GET")
+	r.HandleFunc("/api/tasks/trash/{id}/restore", middleware.AuthMiddleware(
+		controllers.RestoreTask)).Methods("POST")
+	r.HandleFunc("/api/tasks/trash/{id}", middleware.AuthMiddleware(
+		controllers.PurgeTask)).Methods("DELETE")
+
 	// Task management routes
 	r.HandleFunc("/api/tasks", middleware.AuthMiddleware(controllers.CreateTask)).
 		Methods("POST")
@@ -23,10 +43,46 @@ func RegisterTaskRoutes(r *mux.Router) {
 		Methods("GET")
 	r.HandleFunc("/api/tasks/{id}", middleware.AuthMiddleware(controllers.UpdateTask)).
 		Methods("PUT")
+	r.HandleFunc("/api/tasks/{id}", middleware.AuthMiddleware(controllers.PatchTask)).
+		Methods("PATCH")
 	r.HandleFunc("/api/tasks/{id}", middleware.AuthMiddleware(controllers.DeleteTask)).
 		Methods("DELETE")
 	r.HandleFunc("/api/tasks/{id}/status", middleware.AuthMiddleware(
 			controllers.UpdateTaskStatus)).Methods("PATCH")
+	r.HandleFunc("/api/tasks/{id}/move", middleware.AuthMiddleware(
+		controllers.MoveTask)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/archive", middleware.AuthMiddleware(
+		controllers.ArchiveTask)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/unarchive", middleware.AuthMiddleware(
+		controllers.UnarchiveTask)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/activity", middleware.AuthMiddleware(
+		controllers.GetTaskActivity)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/revisions", middleware.AuthMiddleware(
+		controllers.GetTaskRevisions)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/revisions/diff", middleware.AuthMiddleware(
+		controllers.DiffTaskRevisions)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/revisions/{number:[0-9]+}", middleware.AuthMiddleware(
+		controllers.GetTaskRevision)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/revisions/{number:[0-9]+}/restore", middleware.AuthMiddleware(
+		controllers.RestoreTaskRevision)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/subtasks", middleware.AuthMiddleware(
+		controllers.CreateSubtask)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/subtasks", middleware.AuthMiddleware(
+		controllers.GetSubtasks)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/dependencies", middleware.AuthMiddleware(
+		controllers.AddDependency)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/dependencies", middleware.AuthMiddleware(
+		controllers.GetDependencyGraph)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/dependencies/{blockerId}", middleware.AuthMiddleware(
+		controllers.RemoveDependency)).Methods("DELETE")
+	r.HandleFunc("/api/tasks/{id}/attachments", middleware.AuthMiddleware(
+		controllers.UploadAttachment)).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/attachments", middleware.AuthMiddleware(
+		controllers.GetAttachments)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/attachments/{attachmentId}", middleware.AuthMiddleware(
+		controllers.DownloadAttachment)).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}/attachments/{attachmentId}", middleware.AuthMiddleware(
+		controllers.DeleteAttachment)).Methods("DELETE")
 	r.HandleFunc("/api/tasks/collaborators/add", middleware.AuthMiddleware(
 		controllers.AddCollaborator)).Methods("POST")
 	r.HandleFunc("/api/tasks/collaborators/remove", middleware.AuthMiddleware(
@@ -42,6 +98,12 @@ func RegisterTaskRoutes(r *mux.Router) {
 		controllers.UpdateComment)).Methods("PUT")
 	r.HandleFunc("/api/tasks/{taskId}/comments/{commentId}", middleware.AuthMiddleware(
 		controllers.DeleteComment)).Methods("DELETE")
+	r.HandleFunc("/api/tasks/{taskId}/comments/{commentId}/revisions", middleware.AuthMiddleware(
+		controllers.GetCommentRevisions)).Methods("GET")
+	r.HandleFunc("/api/tasks/{taskId}/comments/{commentId}/reactions", middleware.AuthMiddleware(
+		controllers.AddReaction)).Methods("POST")
+	r.HandleFunc("/api/tasks/{taskId}/comments/{commentId}/reactions/{emoji}", middleware.AuthMiddleware(
+		controllers.RemoveReaction)).Methods("DELETE")
 
 	r.HandleFunc("/api/tags", middleware.AuthMiddleware(
 		controllers.GetTags))
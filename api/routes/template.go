@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"api/controllers"
+	"api/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterTemplateRoutes(r *mux.Router) {
+	// Task template routes
+	r.HandleFunc("/api/templates", middleware.AuthMiddleware(
+		controllers.CreateTemplate)).Methods("POST")
+	r.HandleFunc("/api/templates", middleware.AuthMiddleware(
+		controllers.GetTemplates)).Methods("GET")
+	r.HandleFunc("/api/templates/{id}", middleware.AuthMiddleware(
+		controllers.GetTemplate)).Methods("GET")
+	r.HandleFunc("/api/templates/{id}", middleware.AuthMiddleware(
+		controllers.UpdateTemplate)).Methods("PUT")
+	r.HandleFunc("/api/templates/{id}", middleware.AuthMiddleware(
+		controllers.DeleteTemplate)).Methods("DELETE")
+	r.HandleFunc("/api/templates/{id}/instantiate", middleware.AuthMiddleware(
+		controllers.InstantiateTemplate)).Methods("POST")
+}
@@ -24,11 +24,23 @@ func RegisterUserRoutes(r *mux.Router, userController *controllers.UserControlle
 	// Profile picture routes
 	r.HandleFunc("/api/users/profile-picture", middleware.
 		AuthMiddleware(profileController.UpdateProfilePicture)).Methods("POST")
+	r.HandleFunc("/api/uploads/profile_pictures/{fileName}", profileController.ServeProfilePicture).
+		Methods("GET")
 
 	// User preferences routes
 	r.HandleFunc("/api/users/preferences", middleware.
 		AuthMiddleware(profileController.UpdatePreferences)).Methods("PUT")
 
+	// Notification settings routes
+	r.HandleFunc("/api/users/notifications", middleware.
+		AuthMiddleware(profileController.GetNotificationSettings)).Methods("GET")
+	r.HandleFunc("/api/users/notifications", middleware.
+		AuthMiddleware(profileController.UpdateNotificationSettings)).Methods("PUT")
+	r.HandleFunc("/api/users/notifications/unsubscribe", profileController.ConfirmUnsubscribe).
+		Methods("GET")
+	r.HandleFunc("/api/users/notifications/unsubscribe", profileController.Unsubscribe).
+		Methods("POST")
+
 	// Email verification routes
 	r.HandleFunc("/api/users/send-verification", middleware.
 		AuthMiddleware(userController.SendVerificationEmail)).Methods("POST")
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"api/controllers"
+	"api/middleware"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterWorkflowRoutes(r *mux.Router) {
+	r.HandleFunc("/api/workflow", middleware.AuthMiddleware(
+		controllers.GetMyWorkflow)).Methods("GET")
+	r.HandleFunc("/api/workflow", middleware.AuthMiddleware(
+		controllers.UpdateMyWorkflow)).Methods("PUT")
+	r.HandleFunc("/api/projects/{id}/workflow", middleware.AuthMiddleware(
+		controllers.GetProjectWorkflow)).Methods("GET")
+	r.HandleFunc("/api/projects/{id}/workflow", middleware.AuthMiddleware(
+		controllers.UpdateProjectWorkflow)).Methods("PUT")
+}
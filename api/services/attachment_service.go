@@ -0,0 +1,153 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"api/storage"
+	"api/validation"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AttachmentService struct {
+	repo  *repositories.AttachmentRepository
+	store storage.Storage
+}
+
+func NewAttachmentService(repo *repositories.AttachmentRepository, store storage.Storage) *AttachmentService {
+	return &AttachmentService{repo: repo, store: store}
+}
+
+// oleContainer is what legacy Office files (.doc, .xls, .ppt) sniff as
+const oleContainer = "application/x-ole-storage"
+
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// sniffRefinements lists the declared types a sniffed type may stand for,
+// since sniffing can't tell CSV from plain text or a .docx from any other zip
+var sniffRefinements = map[string][]string{
+	"text/plain": {"text/csv", "text/markdown", "application/json"},
+	"application/zip": {
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	},
+	oleContainer: {"application/msword", "application/vnd.ms-excel", "application/vnd.ms-powerpoint"},
+}
+
+// detectContentType sniffs the first bytes of the file. The declared type is
+// only used to narrow down what the content turned out to be, so a client
+// can't get a file past the allowlist by declaring an allowed type.
+func detectContentType(file multipart.File, declared string) (string, error) {
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(buffer[:n]))
+	if sniffed == "application/octet-stream" && bytes.HasPrefix(buffer[:n], oleSignature) {
+		sniffed = oleContainer
+	}
+
+	if declaredType, _, err := mime.ParseMediaType(declared); err == nil && slices.Contains(sniffRefinements[sniffed], declaredType) {
+		return declaredType, nil
+	}
+	return sniffed, nil
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, taskID, uploaderID string, file multipart.File, fileHeader *multipart.FileHeader) (*models.Attachment, error) {
+	if fileHeader.Size > models.MaxAttachmentSize {
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("file exceeds the %d MB limit", models.MaxAttachmentSize>>20))
+	}
+
+	contentType, err := detectContentType(file, fileHeader.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	if !validation.IsValidAttachmentType(contentType) {
+		return nil, errors.NewValidationError(fmt.Sprintf("file type %s is not allowed", contentType), nil)
+	}
+
+	attachment := &models.Attachment{
+		ID:          primitive.NewObjectID(),
+		TaskID:      taskID,
+		UploaderID:  uploaderID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+		CreatedAt:   time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("attachments/%s/%s%s",
+		taskID, attachment.ID.Hex(), strings.ToLower(filepath.Ext(attachment.FileName)))
+
+	if err = s.store.Save(ctx, attachment.StorageKey, file, attachment.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to save file: %v", err)
+	}
+	if err = s.repo.Create(ctx, attachment); err != nil {
+		s.store.Delete(ctx, attachment.StorageKey)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	return s.repo.FindByTasks(ctx, []string{taskID})
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, taskID string, id primitive.ObjectID) (*models.Attachment, error) {
+	attachment, err := s.repo.FindByID(ctx, taskID, id)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewNotFoundError("Attachment", id.Hex())
+	}
+	return attachment, err
+}
+
+// Open returns the content of an attachment; the caller closes it
+func (s *AttachmentService) Open(ctx context.Context, attachment *models.Attachment) (io.ReadCloser, error) {
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err == storage.ErrNotFound {
+		return nil, errors.NewNotFoundError("Attachment", attachment.ID.Hex())
+	}
+	return content, err
+}
+
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, attachment *models.Attachment) error {
+	if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, attachment.ID)
+}
+
+// DeleteForTasks removes every attachment of the given tasks, files included
+func (s *AttachmentService) DeleteForTasks(ctx context.Context, taskIDs []string) error {
+	attachments, err := s.repo.FindByTasks(ctx, taskIDs)
+	if err != nil || len(attachments) == 0 {
+		return err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(attachments))
+	for _, attachment := range attachments {
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+		ids = append(ids, attachment.ID)
+	}
+	return s.repo.DeleteByIDs(ctx, ids)
+}
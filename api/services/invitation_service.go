@@ -0,0 +1,277 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"api/utils"
+	"api/validation"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// invitationTTL is how long an invitation and its emailed links stay valid
+const invitationTTL = 7 * 24 * time.Hour
+
+const invitationLinkPurpose = "invitation"
+
+type InvitationService struct {
+	repo     *repositories.InvitationRepository
+	userRepo *repositories.UserRepository
+}
+
+func NewInvitationService(repo *repositories.InvitationRepository, userRepo *repositories.UserRepository) *InvitationService {
+	return &InvitationService{repo: repo, userRepo: userRepo}
+}
+
+// Invite records a pending invitation to the task and emails the invitee.
+// Re-inviting the same address refreshes the pending invitation instead of
+// creating another one.
+func (s *InvitationService) Invite(ctx context.Context, task *models.Task, inviterID string, request models.InvitationRequest) (*models.Invitation, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if !validation.IsValidEmail(email) {
+		return nil, errors.NewValidationError("invalid email format", nil)
+	}
+	if request.Role == "" {
+		request.Role = models.RoleViewer
+	}
+	if !models.IsValidCollaboratorRole(request.Role) {
+		return nil, errors.NewValidationError("role must be viewer, commenter, or editor", nil)
+	}
+
+	// Link the invitation to an existing account straight away, but only one
+	// that has verified the address; anyone can sign up with any email
+	var inviteeID string
+	invitee, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if invitee != nil {
+		if role := task.RoleFor(invitee.ID.Hex()); role == models.RoleOwner {
+			return nil, errors.NewValidationError("The task owner cannot be invited", nil)
+		} else if role != "" {
+			return nil, errors.NewConflictError("This user is already a collaborator")
+		}
+		if invitee.EmailVerified {
+			inviteeID = invitee.ID.Hex()
+		}
+	}
+
+	now := time.Now()
+	invitation, err := s.repo.FindPending(ctx, task.ID.Hex(), email)
+	switch err {
+	case nil:
+		invitation.Role = request.Role
+		invitation.InviterID = inviterID
+		invitation.InviteeID = inviteeID
+		invitation.ExpiresAt = now.Add(invitationTTL)
+		invitation.UpdatedAt = now
+		if err := s.repo.UpdateInvitation(ctx, invitation.ID, bson.M{
+			"role":       invitation.Role,
+			"inviter_id": invitation.InviterID,
+			"invitee_id": invitation.InviteeID,
+			"expires_at": invitation.ExpiresAt,
+			"updated_at": invitation.UpdatedAt,
+		}); err != nil {
+			return nil, err
+		}
+	case mongo.ErrNoDocuments:
+		invitation = &models.Invitation{
+			TaskID:    task.ID.Hex(),
+			TaskTitle: task.Title,
+			InviterID: inviterID,
+			Email:     email,
+			InviteeID: inviteeID,
+			Role:      request.Role,
+			Status:    models.InvitationPending,
+			ExpiresAt: now.Add(invitationTTL),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if invitation.ID, err = s.repo.Create(ctx, invitation); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := s.sendInvitationEmail(invitation); err != nil {
+		return nil, fmt.Errorf("failed to send invitation email: %v", err)
+	}
+	return invitation, nil
+}
+
+func (s *InvitationService) sendInvitationEmail(invitation *models.Invitation) error {
+	token, err := utils.SignLinkToken(invitationLinkPurpose, invitation.ID.Hex(), invitationTTL)
+	if err != nil {
+		return err
+	}
+
+	respondURL := fmt.Sprintf("%s/api/invitations/respond?token=%s", os.Getenv("APP_URL"), token)
+
+	subject := fmt.Sprintf("You're invited to collaborate on '%s'", invitation.TaskTitle)
+	htmlBody := fmt.Sprintf(`
+		<h2>Task Invitation</h2>
+		<p>You have been invited to collaborate on <strong>%s</strong> as a %s.</p>
+		<p><a href="%s&action=accept">Accept invitation</a> or <a href="%s&action=decline">decline</a>.</p>
+		<p>If you don't have an account yet, sign up with this email address and the invitation will be waiting for you.</p>
+	`, invitation.TaskTitle, invitation.Role, respondURL, respondURL)
+
+	// Invitations can go to people without an account, so there are no preferences to check
+	return utils.SendEmail(invitation.Email, subject, htmlBody)
+}
+
+func (s *InvitationService) ListTaskInvitations(ctx context.Context, taskID string) ([]models.Invitation, error) {
+	return s.repo.FindByTask(ctx, taskID)
+}
+
+func (s *InvitationService) ListPendingInvitations(ctx context.Context, userID string) ([]models.Invitation, error) {
+	return s.repo.FindPendingByInvitee(ctx, userID)
+}
+
+// AddCollaboratorFunc puts the user an accepted invitation was for on its task.
+// The task controller provides it, so invitees join through the same path as
+// collaborators added directly.
+type AddCollaboratorFunc func(ctx context.Context, invitation *models.Invitation, userID string) error
+
+// Respond accepts or declines an invitation on behalf of a signed-in user
+func (s *InvitationService) Respond(ctx context.Context, invitationID primitive.ObjectID, userID string, accept bool, addCollaborator AddCollaboratorFunc) error {
+	invitation, err := s.getPendingInvitation(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.NewValidationError("Invalid user ID", nil)
+	}
+	user, err := s.userRepo.FindByID(ctx, userObjectID)
+	if err != nil {
+		return err
+	}
+	// Matching by address only counts once the user has verified it
+	ownsAddress := user.EmailVerified && invitation.Email == strings.ToLower(user.Email)
+	if invitation.InviteeID != userID && !ownsAddress {
+		return errors.NewNotFoundError("Invitation", invitationID.Hex())
+	}
+
+	return s.resolve(ctx, invitation, userID, accept, addCollaborator)
+}
+
+// InvitationForToken returns the pending invitation a signed link from the
+// invitation email was made for, without acting on it
+func (s *InvitationService) InvitationForToken(ctx context.Context, token string) (*models.Invitation, error) {
+	subject, err := utils.ParseLinkToken(invitationLinkPurpose, token)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+	invitationID, err := primitive.ObjectIDFromHex(subject)
+	if err != nil {
+		return nil, errors.NewValidationError("invalid or expired link", nil)
+	}
+	return s.getPendingInvitation(ctx, invitationID)
+}
+
+// RespondWithToken handles the signed links from the invitation email. When
+// there is no account with a verified address yet, an acceptance is held until
+// the address is verified, so it can't go to someone who merely signed up with it.
+func (s *InvitationService) RespondWithToken(ctx context.Context, token string, accept bool, addCollaborator AddCollaboratorFunc) (string, error) {
+	invitation, err := s.InvitationForToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	invitee, err := s.userRepo.FindByEmail(ctx, invitation.Email)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+	if invitee == nil || !invitee.EmailVerified {
+		if !accept {
+			return models.InvitationDeclined, s.resolve(ctx, invitation, "", false, addCollaborator)
+		}
+		if err := s.repo.UpdateInvitation(ctx, invitation.ID, bson.M{
+			"accept_on_signup": true,
+			"updated_at":       time.Now(),
+		}); err != nil {
+			return "", err
+		}
+		return models.InvitationPending, nil
+	}
+
+	if err := s.resolve(ctx, invitation, invitee.ID.Hex(), accept, addCollaborator); err != nil {
+		return "", err
+	}
+	if accept {
+		return models.InvitationAccepted, nil
+	}
+	return models.InvitationDeclined, nil
+}
+
+// ClaimPendingInvitations is called once a user verifies their address so
+// invitations sent to it show up for the account, and ones already accepted by
+// link take effect.
+func (s *InvitationService) ClaimPendingInvitations(ctx context.Context, userID string, email string, addCollaborator AddCollaboratorFunc) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if err := s.repo.ClaimByEmail(ctx, email, userID); err != nil {
+		return err
+	}
+
+	invitations, err := s.repo.FindPendingByInvitee(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for i := range invitations {
+		if invitations[i].AcceptOnSignup {
+			if err := s.resolve(ctx, &invitations[i], userID, true, addCollaborator); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *InvitationService) getPendingInvitation(ctx context.Context, invitationID primitive.ObjectID) (*models.Invitation, error) {
+	invitation, err := s.repo.FindByID(ctx, invitationID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundError("Invitation", invitationID.Hex())
+		}
+		return nil, err
+	}
+	if invitation.Status != models.InvitationPending {
+		return nil, errors.NewConflictError("Invitation has already been " + invitation.Status)
+	}
+	if invitation.IsExpired() {
+		return nil, errors.NewValidationError("Invitation has expired", nil)
+	}
+	return invitation, nil
+}
+
+// resolve records the answer and, on acceptance, turns the invitation into a collaborator entry
+func (s *InvitationService) resolve(ctx context.Context, invitation *models.Invitation, userID string, accept bool, addCollaborator AddCollaboratorFunc) error {
+	now := time.Now()
+	status := models.InvitationDeclined
+	if accept {
+		status = models.InvitationAccepted
+		if err := addCollaborator(ctx, invitation, userID); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{
+		"status":       status,
+		"responded_at": now,
+		"updated_at":   now,
+	}
+	if userID != "" {
+		update["invitee_id"] = userID
+	}
+	return s.repo.UpdateInvitation(ctx, invitation.ID, update)
+}
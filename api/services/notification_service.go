@@ -0,0 +1,45 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type NotificationService struct {
+	repo *repositories.NotificationRepository
+}
+
+func NewNotificationService(repo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+func (s *NotificationService) List(ctx context.Context, userID string, unreadOnly bool, page, limit int64) ([]models.Notification, int64, error) {
+	return s.repo.FindPage(ctx, userID, unreadOnly, page, limit)
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks one of the user's notifications as read and returns it
+func (s *NotificationService) MarkRead(ctx context.Context, id primitive.ObjectID, userID string) (*models.Notification, error) {
+	if err := s.repo.MarkRead(ctx, id, userID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	notification, err := s.repo.FindByID(ctx, id, userID)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewNotFoundError("Notification", id.Hex())
+	}
+	return notification, err
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID, time.Now())
+}
@@ -1,14 +1,16 @@
 package services
 
 import (
+	"api/errors"
 	"api/models"
+	"api/notify"
 	"api/repositories"
+	"api/storage"
 	"api/validation"
 	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
 	"path/filepath"
 	"time"
 
@@ -18,36 +20,30 @@ import (
 
 type ProfileService struct {
 	userRepo *repositories.UserRepository
+	store    storage.Storage
 }
 
-func NewProfileService(userRepo *repositories.UserRepository) *ProfileService {
-	return &ProfileService{userRepo: userRepo}
+func NewProfileService(userRepo *repositories.UserRepository, store storage.Storage) *ProfileService {
+	return &ProfileService{userRepo: userRepo, store: store}
 }
 
+// profilePicturePrefix is where profile pictures live in file storage
+const profilePicturePrefix = "profile_pictures/"
+
 func (s *ProfileService) UpdateProfilePicture(ctx context.Context, userID primitive.ObjectID, file multipart.File, fileHeader *multipart.FileHeader) (*models.ProfilePictureResponse, error) {
 	// Validate file type
 	if !validation.IsValidImageType(fileHeader.Header.Get("Content-Type")) {
 		return nil, fmt.Errorf("invalid file type. Only images are allowed")
 	}
 
-	// Create uploads directory if it doesn't exist
-	uploadsDir := "uploads/profile_pictures"
-	os.MkdirAll(uploadsDir, os.ModePerm)
-
 	// Generate unique filename
 	timestamp := time.Now().Unix()
 	fileExt := filepath.Ext(fileHeader.Filename)
 	fileName := fmt.Sprintf("%s_%d%s", userID.Hex(), timestamp, fileExt)
-	filePath := filepath.Join(uploadsDir, fileName)
+	filePath := profilePicturePrefix + fileName
 
 	// Save file
-	dst, err := os.Create(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to save file: %v", err)
-	}
-	defer dst.Close()
-
-	if _, err = io.Copy(dst, file); err != nil {
+	if err := s.store.Save(ctx, filePath, file, fileHeader.Size, fileHeader.Header.Get("Content-Type")); err != nil {
 		return nil, fmt.Errorf("failed to save file: %v", err)
 	}
 
@@ -79,11 +75,83 @@ func (s *ProfileService) UpdateProfilePicture(ctx context.Context, userID primit
 	}, nil
 }
 
+// OpenProfilePicture returns the stored picture with the given file name; the caller closes it
+func (s *ProfileService) OpenProfilePicture(ctx context.Context, fileName string) (io.ReadCloser, error) {
+	if fileName == "" || fileName != filepath.Base(fileName) {
+		return nil, storage.ErrNotFound
+	}
+	return s.store.Open(ctx, profilePicturePrefix+fileName)
+}
+
 func (s *ProfileService) UpdatePreferences(ctx context.Context, userID primitive.ObjectID, preferences models.UserPreferencesUpdate) error {
+	// Email dates are rendered in this zone, so it has to be one we can load.
+	// Leaving it empty means UTC.
+	if preferences.Timezone != "" && !validation.IsValidTimezone(preferences.Timezone) {
+		return errors.NewValidationError(fmt.Sprintf("unknown timezone %q", preferences.Timezone), nil)
+	}
+
+	// Set fields one by one so the per-event notification settings are kept
 	update := bson.M{
-		"preferences": preferences,
-		"updated_at": time.Now(),
+		"preferences.timezone":            preferences.Timezone,
+		"preferences.email_notifications": preferences.EmailNotifications,
+		"preferences.push_notifications":  preferences.PushNotifications,
+		"preferences.daily_digest":        preferences.DailyDigest,
+		"updated_at":                      time.Now(),
+	}
+
+	return s.userRepo.UpdateUser(ctx, userID, update)
+}
+
+func (s *ProfileService) GetNotificationSettings(ctx context.Context, userID primitive.ObjectID) (*models.NotificationSettings, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Preferences.Notifications, nil
+}
+
+func (s *ProfileService) UpdateNotificationSettings(ctx context.Context, userID primitive.ObjectID, settings models.NotificationSettings) error {
+	update := bson.M{
+		"preferences.notifications": settings,
+		"updated_at":                time.Now(),
 	}
 
 	return s.userRepo.UpdateUser(ctx, userID, update)
+}
+
+// UnsubscribeEvent checks an unsubscribe token without acting on it and
+// returns the event it turns off
+func (s *ProfileService) UnsubscribeEvent(token string) (string, error) {
+	_, event, err := s.parseUnsubscribeToken(token)
+	return event, err
+}
+
+func (s *ProfileService) parseUnsubscribeToken(token string) (primitive.ObjectID, string, error) {
+	userID, event, err := notify.ParseUnsubscribeToken(token)
+	if err != nil {
+		return primitive.NilObjectID, "", errors.NewValidationError("Invalid or expired unsubscribe link", nil)
+	}
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return primitive.NilObjectID, "", errors.NewValidationError("Invalid or expired unsubscribe link", nil)
+	}
+	return id, event, nil
+}
+
+// Unsubscribe turns off the event named in a signed unsubscribe link and
+// returns it
+func (s *ProfileService) Unsubscribe(ctx context.Context, token string) (string, error) {
+	id, event, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	update := bson.M{
+		"preferences.notifications." + event: false,
+		"updated_at":                         time.Now(),
+	}
+	if err = s.userRepo.UpdateUser(ctx, id, update); err != nil {
+		return "", err
+	}
+	return event, nil
 }
\ No newline at end of file
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ProjectService struct {
+	repo     *repositories.ProjectRepository
+	userRepo *repositories.UserRepository
+}
+
+func NewProjectService(repo *repositories.ProjectRepository, userRepo *repositories.UserRepository) *ProjectService {
+	return &ProjectService{repo: repo, userRepo: userRepo}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, ownerID string, project *models.Project) (primitive.ObjectID, error) {
+	now := time.Now()
+	project.OwnerID = ownerID
+	project.Members = []models.ProjectMember{{UserID: ownerID, Role: "owner", AddedAt: now}}
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	if err := project.Validate(); err != nil {
+		return primitive.NilObjectID, errors.NewValidationError(err.Error(), nil)
+	}
+
+	return s.repo.Create(ctx, project)
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	return s.repo.FindByMember(ctx, userID)
+}
+
+// GetProject returns a project the user is a member of
+func (s *ProjectService) GetProject(ctx context.Context, projectID primitive.ObjectID, userID string) (*models.Project, error) {
+	project, err := s.repo.FindByID(ctx, projectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundError("Project", projectID.Hex())
+		}
+		return nil, err
+	}
+
+	// Non-members get the same answer as for a missing project
+	if !project.HasMember(userID) {
+		return nil, errors.NewNotFoundError("Project", projectID.Hex())
+	}
+	return project, nil
+}
+
+// getOwnedProject returns a project only if the user owns it
+func (s *ProjectService) getOwnedProject(ctx context.Context, projectID primitive.ObjectID, userID string) (*models.Project, error) {
+	project, err := s.GetProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if project.OwnerID != userID {
+		return nil, errors.NewForbiddenError("Only the project owner can do this")
+	}
+	return project, nil
+}
+
+func (s *ProjectService) UpdateProject(ctx context.Context, projectID primitive.ObjectID, userID string, update *models.Project) (*models.Project, error) {
+	project, err := s.getOwnedProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project.Name = update.Name
+	project.Description = update.Description
+	project.UpdatedAt = time.Now()
+	if err := project.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+
+	if err := s.repo.UpdateProject(ctx, projectID, bson.M{
+		"name":        project.Name,
+		"description": project.Description,
+		"updated_at":  project.UpdatedAt,
+	}); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// DeleteProject removes a project the user owns. detachTasks runs first so
+// the project's tasks stay with their owners; tasks are not this service's to
+// write.
+func (s *ProjectService) DeleteProject(ctx context.Context, projectID primitive.ObjectID, userID string, detachTasks func(ctx context.Context, projectID string) error) error {
+	if _, err := s.getOwnedProject(ctx, projectID, userID); err != nil {
+		return err
+	}
+	if err := detachTasks(ctx, projectID.Hex()); err != nil {
+		return err
+	}
+	return s.repo.DeleteProject(ctx, projectID)
+}
+
+func (s *ProjectService) AddMember(ctx context.Context, projectID primitive.ObjectID, userID string, memberID string) error {
+	if _, err := s.getOwnedProject(ctx, projectID, userID); err != nil {
+		return err
+	}
+
+	memberObjectID, err := primitive.ObjectIDFromHex(memberID)
+	if err != nil {
+		return errors.NewValidationError("Invalid user ID", nil)
+	}
+	if _, err := s.userRepo.FindByID(ctx, memberObjectID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.NewNotFoundError("User", memberID)
+		}
+		return err
+	}
+
+	return s.repo.AddMember(ctx, projectID, models.ProjectMember{
+		UserID:  memberID,
+		Role:    "member",
+		AddedAt: time.Now(),
+	})
+}
+
+// RemoveMember lets the owner remove anyone but themselves, and members leave on their own
+func (s *ProjectService) RemoveMember(ctx context.Context, projectID primitive.ObjectID, userID string, memberID string) error {
+	project, err := s.GetProject(ctx, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == project.OwnerID {
+		return errors.NewValidationError("The project owner cannot be removed", nil)
+	}
+	if project.OwnerID != userID && memberID != userID {
+		return errors.NewForbiddenError("Only the project owner can remove other members")
+	}
+
+	return s.repo.RemoveMember(ctx, projectID, memberID)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type TemplateService struct {
+	repo *repositories.TemplateRepository
+}
+
+func NewTemplateService(repo *repositories.TemplateRepository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+func (s *TemplateService) CreateTemplate(ctx context.Context, ownerID string, template *models.TaskTemplate) (*models.TaskTemplate, error) {
+	now := time.Now()
+	template.ID = primitive.NilObjectID
+	template.OwnerID = ownerID
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	if err := template.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+
+	id, err := s.repo.Create(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = id
+	return template, nil
+}
+
+func (s *TemplateService) ListTemplates(ctx context.Context, ownerID string) ([]models.TaskTemplate, error) {
+	return s.repo.FindByOwner(ctx, ownerID)
+}
+
+// GetTemplate returns one of the user's templates; other users' templates are reported missing
+func (s *TemplateService) GetTemplate(ctx context.Context, id primitive.ObjectID, ownerID string) (*models.TaskTemplate, error) {
+	template, err := s.repo.FindByID(ctx, id, ownerID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundError("Template", id.Hex())
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *TemplateService) UpdateTemplate(ctx context.Context, id primitive.ObjectID, ownerID string, update *models.TaskTemplate) (*models.TaskTemplate, error) {
+	existing, err := s.GetTemplate(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	update.ID = existing.ID
+	update.OwnerID = existing.OwnerID
+	update.CreatedAt = existing.CreatedAt
+	update.UpdatedAt = time.Now()
+	if err := update.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+
+	if err := s.repo.Replace(ctx, update); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id primitive.ObjectID, ownerID string) error {
+	deleted, err := s.repo.Delete(ctx, id, ownerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.NewNotFoundError("Template", id.Hex())
+	}
+	return nil
+}
@@ -36,10 +36,17 @@ func (s *UserService) CreateUser(ctx context.Context, user *models.User) (primit
 		return primitive.NilObjectID, err
 	}
 
-	// Set user fields
+	// Set user fields; verification and the rest are never taken from the client
+	user.ID = primitive.NilObjectID
+	user.EmailVerified = false
+	user.VerificationToken = ""
+	user.ProfilePicture = nil
+	user.LastDigestDate = ""
 	user.Password = hashedPassword
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	user.Preferences.EmailNotifications = true
+	user.Preferences.Notifications = models.DefaultNotificationSettings()
 
 	return s.repo.Create(ctx, user)
 }
@@ -149,14 +156,16 @@ func (s *UserService) SendVerificationEmail(ctx context.Context, userID primitiv
 		<p>If you didn't request this, please ignore this email.</p>
 	`, verificationURL)
 
+	// Verification was asked for, so it is sent whatever the notification settings
 	return utils.SendEmail(user.Email, subject, htmlBody)
 }
 
-func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+// VerifyEmail marks the address of the user holding the token as verified and returns the user
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
 	// Find user by verification token
 	user, err := s.repo.FindByVerificationToken(ctx, token)
 	if err != nil {
-		return errors.New("invalid or expired verification token")
+		return nil, errors.New("invalid or expired verification token")
 	}
 
 	// Update user as verified
@@ -166,5 +175,9 @@ func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
 		"updated_at":        time.Now(),
 	}
 
-	return s.repo.UpdateUser(ctx, user.ID, update)
+	if err = s.repo.UpdateUser(ctx, user.ID, update); err != nil {
+		return nil, err
+	}
+	user.EmailVerified = true
+	return user, nil
 }
\ No newline at end of file
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"api/errors"
+	"api/models"
+	"api/repositories"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GovernedTasks reads and updates the tasks a workflow governs: a project's
+// tasks, or a user's personal ones when projectID is empty. The task
+// controller provides it, as the tasks collection is handled there.
+type GovernedTasks interface {
+	// CountOutside counts the governed tasks whose status is not in statuses
+	CountOutside(ctx context.Context, ownerID, projectID string, statuses []string) (int64, error)
+	// SyncCompletion sets or clears completed_at on the governed tasks after
+	// the set of done statuses has changed
+	SyncCompletion(ctx context.Context, ownerID, projectID string, doneStatuses []string) error
+}
+
+type WorkflowService struct {
+	repo     *repositories.WorkflowRepository
+	projects *ProjectService
+	tasks    GovernedTasks
+}
+
+func NewWorkflowService(repo *repositories.WorkflowRepository, projects *ProjectService, tasks GovernedTasks) *WorkflowService {
+	return &WorkflowService{repo: repo, projects: projects, tasks: tasks}
+}
+
+// Resolve returns the workflow that governs tasks in a project, or a user's
+// personal tasks when projectID is empty, falling back to the default workflow
+func (s *WorkflowService) Resolve(ctx context.Context, ownerID, projectID string) (*models.Workflow, error) {
+	workflow, err := s.repo.FindByScope(ctx, ownerID, projectID)
+	if err == mongo.ErrNoDocuments {
+		workflow = models.DefaultWorkflow()
+		if projectID != "" {
+			workflow.ProjectID = projectID
+		} else {
+			workflow.OwnerID = ownerID
+		}
+		return workflow, nil
+	}
+	return workflow, err
+}
+
+func (s *WorkflowService) GetProjectWorkflow(ctx context.Context, projectID primitive.ObjectID, userID string) (*models.Workflow, error) {
+	if _, err := s.projects.GetProject(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.Resolve(ctx, "", projectID.Hex())
+}
+
+func (s *WorkflowService) UpdateUserWorkflow(ctx context.Context, userID string, request models.WorkflowRequest) (*models.Workflow, error) {
+	return s.save(ctx, &models.Workflow{
+		OwnerID:     userID,
+		Statuses:    request.Statuses,
+		Transitions: request.Transitions,
+	})
+}
+
+// UpdateProjectWorkflow replaces a project's workflow; only the project owner can do this
+func (s *WorkflowService) UpdateProjectWorkflow(ctx context.Context, projectID primitive.ObjectID, userID string, request models.WorkflowRequest) (*models.Workflow, error) {
+	if _, err := s.projects.getOwnedProject(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.save(ctx, &models.Workflow{
+		ProjectID:   projectID.Hex(),
+		Statuses:    request.Statuses,
+		Transitions: request.Transitions,
+	})
+}
+
+// save stores a workflow once no task would be left in a status it drops, and
+// brings completed_at in line with the new done statuses
+func (s *WorkflowService) save(ctx context.Context, workflow *models.Workflow) (*models.Workflow, error) {
+	if err := workflow.Validate(); err != nil {
+		return nil, errors.NewValidationError(err.Error(), nil)
+	}
+
+	stranded, err := s.tasks.CountOutside(ctx, workflow.OwnerID, workflow.ProjectID, workflow.StatusNames())
+	if err != nil {
+		return nil, err
+	}
+	if stranded > 0 {
+		return nil, errors.NewConflictError(fmt.Sprintf("%d tasks are in statuses this workflow removes; move them first", stranded))
+	}
+
+	if err = s.repo.Save(ctx, workflow); err != nil {
+		return nil, err
+	}
+	if err = s.tasks.SyncCompletion(ctx, workflow.OwnerID, workflow.ProjectID, workflow.DoneStatuses()); err != nil {
+		return nil, err
+	}
+	return s.Resolve(ctx, workflow.OwnerID, workflow.ProjectID)
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"api/configs"
+	"api/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BackfillCompletedAt sets completed_at on tasks completed before it was
+// recorded. Jobs rely on it to tell done tasks apart without loading every
+// workflow; those tasks predate custom workflows, so the default one applies.
+func BackfillCompletedAt() error {
+	taskCollection := configs.GetCollection(configs.DB, "tasks")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := taskCollection.UpdateMany(ctx, bson.M{
+		"status":       bson.M{"$in": models.DefaultWorkflow().DoneStatuses()},
+		"completed_at": bson.M{"$exists": false},
+	}, []bson.M{
+		{"$set": bson.M{"completed_at": "$updated_at"}},
+	})
+	return err
+}
+
+// BackfillReminders moves tasks from the single hour_reminder_sent flag to the
+// reminders list, keeping their one-hour reminder and whether it went out
+func BackfillReminders() error {
+	taskCollection := configs.GetCollection(configs.DB, "tasks")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hourBefore := bson.M{"$subtract": bson.A{"$due_date", int64(time.Hour / time.Millisecond)}}
+	_, err := taskCollection.UpdateMany(ctx, bson.M{
+		"reminders": bson.M{"$exists": false},
+	}, []bson.M{
+		{"$set": bson.M{"reminders": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$hour_reminder_sent", true}},
+			bson.A{bson.M{"offset_minutes": 60, "remind_at": hourBefore, "sent_at": "$$NOW"}},
+			bson.A{bson.M{"offset_minutes": 60, "remind_at": hourBefore}},
+		}}}},
+		{"$unset": "hour_reminder_sent"},
+	})
+	return err
+}
+
+// BackfillNotificationSettings turns every event on for users who signed up
+// before notifications could be turned off one by one. Whether they get email
+// at all stays up to their email_notifications preference, which is only
+// filled in, as on, for users who have none stored.
+func BackfillNotificationSettings() error {
+	userCollection := configs.GetCollection(configs.DB, "users")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := userCollection.UpdateMany(ctx, bson.M{
+		"preferences.notifications": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"preferences.notifications": models.DefaultNotificationSettings()},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = userCollection.UpdateMany(ctx, bson.M{
+		"preferences.email_notifications": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"preferences.email_notifications": true},
+	})
+	return err
+}
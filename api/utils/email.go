@@ -2,11 +2,17 @@ package utils
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
 func SendEmail(to string, subject string, htmlBody string) error {
+	return SendEmailWithHeaders(to, subject, htmlBody, nil)
+}
+
+// SendEmailWithHeaders sends an email with extra headers such as List-Unsubscribe
+func SendEmailWithHeaders(to string, subject string, htmlBody string, headers map[string]string) error {
 	from := os.Getenv("APP_EMAIL")
 	password := os.Getenv("APP_EMAIL_PASSWORD")
 	smtpHost := os.Getenv("SMTP_HOST")
@@ -17,9 +23,20 @@ func SendEmail(to string, subject string, htmlBody string) error {
 	m.SetHeader("From", from)
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
+	for name, value := range headers {
+		m.SetHeader(name, value)
+	}
 	m.SetBody("text/html", htmlBody)
 
 	// Dial and send
 	d := gomail.NewDialer(smtpHost, smtpPort, from, password)
 	return d.DialAndSend(m)
 }
+
+// EmailDateLayout is how dates appear in emails; the zone is always shown
+const EmailDateLayout = "Jan 2, 2006 15:04 MST"
+
+// FormatEmailDate renders a date for an email in the recipient's timezone
+func FormatEmailDate(t time.Time, location *time.Location) string {
+	return t.In(location).Format(EmailDateLayout)
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"api/errors"
+	"api/logger"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -21,6 +23,18 @@ func SendJSON(w http.ResponseWriter, data any) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// SendAppError writes an AppError with its own status code. Any other error
+// is logged and answered with the fallback status and a generic message, so
+// database errors never reach clients.
+func SendAppError(w http.ResponseWriter, err error, fallback int) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		SendError(w, appErr.Message, appErr.Code)
+		return
+	}
+	logger.ErrorLogger.Printf("Request failed: %v", err)
+	SendError(w, http.StatusText(fallback), fallback)
+}
+
 func VerifyOwnership(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, userID string, result interface{}) error {
 	return collection.FindOne(ctx, bson.M{"_id": id, "user_id": userID}).Decode(result)
 }
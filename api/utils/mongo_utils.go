@@ -20,6 +20,8 @@ type PaginationParams struct {
 	Search   string
 	Priority string
 	Status   string
+	TopLevel bool
+	Archived bool
 }
 
 type DateRange struct {
@@ -47,6 +49,8 @@ func GetPaginationFromRequest(r *http.Request) PaginationParams {
 		Search:   query.Get("search"),
 		Priority: query.Get("priority"),
 		Status:   query.Get("status"),
+		TopLevel: query.Get("top_level") == "true",
+		Archived: query.Get("archived") == "true",
 	}
 }
 
@@ -54,10 +58,17 @@ func BuildSearchFilter(baseFilter bson.M, params PaginationParams, dateRange *Da
 	filter := baseFilter
 
 	if params.Search != "" {
-		filter["$or"] = []bson.M{
+		search := []bson.M{
 			{"title": bson.M{"$regex": params.Search, "$options": "i"}},
 			{"description": bson.M{"$regex": params.Search, "$options": "i"}},
 		}
+		// Keep an $or from the base filter (e.g. access rules) instead of replacing it
+		if baseOr, ok := filter["$or"]; ok {
+			delete(filter, "$or")
+			filter["$and"] = []bson.M{{"$or": baseOr}, {"$or": search}}
+		} else {
+			filter["$or"] = search
+		}
 	}
 
 	if params.Priority != "" {
@@ -68,6 +79,13 @@ func BuildSearchFilter(baseFilter bson.M, params PaginationParams, dateRange *Da
 		filter["status"] = params.Status
 	}
 
+	if params.TopLevel {
+		filter["parent_id"] = bson.M{"$exists": false}
+	}
+
+	// Archived tasks are left out unless explicitly asked for
+	filter["archived_at"] = bson.M{"$exists": params.Archived}
+
 	if dateRange != nil {
 		dateFilter := bson.M{}
 		if dateRange.StartDate != "" {
@@ -98,7 +116,7 @@ func BuildSortOptions(params PaginationParams) bson.M {
 			sortDirection = -1
 		}
 		switch params.SortBy {
-		case "due_date", "priority", "status", "title":
+		case "due_date", "priority", "status", "title", "rank":
 			sortOptions = bson.M{params.SortBy: sortDirection}
 		}
 	}
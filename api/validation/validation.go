@@ -2,6 +2,7 @@ package validation
 
 import (
 	"errors"
+	"net/mail"
 	"regexp"
 	"time"
 )
@@ -37,7 +38,18 @@ func ValidatePassword(password string) error {
 	return nil
 }
 
+func IsValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+// IsValidTimezone reports whether tz is an IANA zone name such as
+// Europe/Berlin. "" and "Local" load without error but stand for UTC and the
+// server's own zone, so they are rejected.
 func IsValidTimezone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
 	_, err := time.LoadLocation(tz)
 	return err == nil
 }
@@ -50,3 +62,26 @@ func IsValidImageType(contentType string) bool {
 	}
 	return validTypes[contentType]
 }
+
+// IsValidAttachmentType accepts images, PDFs, plain text and common office and archive formats
+func IsValidAttachmentType(contentType string) bool {
+	validTypes := map[string]bool{
+		"image/jpeg":         true,
+		"image/png":          true,
+		"image/gif":          true,
+		"image/webp":         true,
+		"application/pdf":    true,
+		"text/plain":         true,
+		"text/csv":           true,
+		"text/markdown":      true,
+		"application/json":   true,
+		"application/zip":    true,
+		"application/msword": true,
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+		"application/vnd.ms-excel": true,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+		"application/vnd.ms-powerpoint":                                             true,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+	}
+	return validTypes[contentType]
+}